@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -29,23 +30,37 @@ func LoadAWSConfig(ctx context.Context, profile string) (aws.Config, error) {
 		if sessionToken != "" {
 			// All three environment variables are present
 			fmt.Printf("Using temporary credentials with session token\n")
-			cfg, err = config.LoadDefaultConfig(ctx,
+			cfg, err = config.LoadDefaultConfig(ctx, config.WithHTTPClient(HTTPClient()),
 				config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 					accessKeyID, secretAccessKey, sessionToken)))
 		} else {
 			// Only access key and secret key are present
 			fmt.Printf("Using long-term credentials (access key + secret key)\n")
-			cfg, err = config.LoadDefaultConfig(ctx,
+			cfg, err = config.LoadDefaultConfig(ctx, config.WithHTTPClient(HTTPClient()),
 				config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 					accessKeyID, secretAccessKey, "")))
 		}
 	} else {
 		// Fall back to profile-based configuration
 		fmt.Printf("Using AWS profile: %s\n", profile)
+		// Proxy and CA bundle settings apply to all clients built from the config
+		optFns := []func(*config.LoadOptions) error{config.WithHTTPClient(HTTPClient())}
+		name := "default"
 		if profile != "" && profile != "default" {
-			cfg, err = config.LoadDefaultConfig(ctx, config.WithSharedConfigProfile(profile))
-		} else {
-			cfg, err = config.LoadDefaultConfig(ctx)
+			name = profile
+			optFns = append(optFns, config.WithSharedConfigProfile(profile))
+		}
+		// Assume-role and SSO credentials use the endpoint overrides,
+		// roles with mfa_serial ask for the MFA code, the session credentials are reused
+		sharedProfile, findErr := FindProfile(name)
+		if findErr != nil {
+			sharedProfile = Profile{Name: name}
+		}
+		mfa := sharedProfile.MFASerial != ""
+		optFns = append(optFns, credentialOptions(sharedProfile, mfa)...)
+		cfg, err = config.LoadDefaultConfig(ctx, optFns...)
+		if err == nil && mfa {
+			cfg.Credentials = cachedSessionCredentials(sharedProfile, cfg.Credentials)
 		}
 	}
 
@@ -58,19 +73,58 @@ func LoadAWSConfig(ctx context.Context, profile string) (aws.Config, error) {
 
 // ValidateAWSConfig validates the AWS configuration by calling STS GetCallerIdentity
 func ValidateAWSConfig(ctx context.Context, cfg aws.Config) error {
-	stsClient := sts.NewFromConfig(cfg)
-	identity, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	account, arn, err := GetCallerIdentity(ctx, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to verify AWS identity: %w", err)
+		return err
 	}
 
-	fmt.Printf("AWS identity validated - Account: %s, User/Role: %s\n", 
-		aws.ToString(identity.Account), aws.ToString(identity.Arn))
+	fmt.Printf("AWS identity validated - Account: %s, User/Role: %s\n", account, arn)
 	return nil
 }
 
+// GetCallerIdentity returns the account ID and ARN of the configured credentials
+func GetCallerIdentity(ctx context.Context, cfg aws.Config) (string, string, error) {
+	stsClient := NewSTSClient(cfg)
+	identity, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to verify AWS identity: %w", err)
+	}
+	return aws.ToString(identity.Account), aws.ToString(identity.Arn), nil
+}
+
+// PartitionFromARN returns the partition of an ARN, e.g. "aws" or "aws-cn"
+func PartitionFromARN(arn string) string {
+	parts := strings.SplitN(arn, ":", 3)
+	if len(parts) < 3 || parts[1] == "" {
+		return "aws"
+	}
+	return parts[1]
+}
+
+// SSOLoginHandler is called by LoadAndValidateAWSConfig before the profile is used,
+// e.g. to run the SSO device authorization flow for an expired token in the UI.
+// It blocks until the login is done, nil skips the login
+var SSOLoginHandler func(profile string) error
+
+// loginIfRequired calls the SSOLoginHandler for the profile
+// Credentials from environment variables take precedence over the profile and need no login
+func loginIfRequired(profile string) error {
+	envCredentials := os.Getenv("AWS_ACCESS_KEY_ID") != "" && os.Getenv("AWS_SECRET_ACCESS_KEY") != ""
+	if SSOLoginHandler == nil || envCredentials {
+		return nil
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	return SSOLoginHandler(profile)
+}
+
 // LoadAndValidateAWSConfig is a convenience function that loads and validates AWS config
 func LoadAndValidateAWSConfig(ctx context.Context, profile string) (aws.Config, error) {
+	if err := loginIfRequired(profile); err != nil {
+		return aws.Config{}, err
+	}
+
 	cfg, err := LoadAWSConfig(ctx, profile)
 	if err != nil {
 		return cfg, err
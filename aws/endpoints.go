@@ -0,0 +1,205 @@
+package aws
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/bedrock"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/transcribe"
+)
+
+// Endpoints overrides the service endpoints, e.g. with VPC interface endpoints or LocalStack
+// Empty URLs keep the default endpoint of the region
+type Endpoints struct {
+	S3         string
+	Transcribe string
+	STS        string
+	// Bedrock is the endpoint of the Bedrock runtime, which runs the models
+	Bedrock string
+	// BedrockControl is the endpoint of the Bedrock control plane, which lists the models
+	BedrockControl string
+	// SSO is the endpoint of the SSO portal, which returns the role credentials of SSO profiles
+	SSO string
+	// SSOOIDC is the endpoint of SSO OIDC, which logs in and refreshes the SSO token
+	SSOOIDC string
+	// S3PathStyle addresses buckets as https://host/bucket instead of https://bucket.host
+	S3PathStyle bool
+}
+
+// Validate checks that all endpoint URLs are absolute http or https URLs
+func (e Endpoints) Validate() error {
+	for service, endpoint := range map[string]string{
+		"S3": e.S3, "Transcribe": e.Transcribe, "STS": e.STS, "Bedrock": e.Bedrock,
+		"Bedrock control": e.BedrockControl, "SSO": e.SSO, "SSO OIDC": e.SSOOIDC,
+	} {
+		if endpoint == "" {
+			continue
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s endpoint %q is not a http or https URL", service, endpoint)
+		}
+	}
+	return nil
+}
+
+var (
+	endpointsMu sync.Mutex
+	endpoints   Endpoints
+)
+
+// SetEndpoints sets the endpoint overrides for all clients created afterwards
+// A change drops the current session and the MFA session credentials, so they are created again
+func SetEndpoints(e Endpoints) {
+	e.S3 = strings.TrimSpace(e.S3)
+	e.Transcribe = strings.TrimSpace(e.Transcribe)
+	e.STS = strings.TrimSpace(e.STS)
+	e.Bedrock = strings.TrimSpace(e.Bedrock)
+	e.BedrockControl = strings.TrimSpace(e.BedrockControl)
+	e.SSO = strings.TrimSpace(e.SSO)
+	e.SSOOIDC = strings.TrimSpace(e.SSOOIDC)
+
+	endpointsMu.Lock()
+	changed := e != endpoints
+	endpoints = e
+	endpointsMu.Unlock()
+
+	if changed {
+		fmt.Printf("AWS endpoints: S3 %q, Transcribe %q, STS %q, Bedrock %q, Bedrock control %q, SSO %q, SSO OIDC %q, S3 path-style %t\n",
+			e.S3, e.Transcribe, e.STS, e.Bedrock, e.BedrockControl, e.SSO, e.SSOOIDC, e.S3PathStyle)
+		sessionMu.Lock()
+		dropSession()
+		sessionMu.Unlock()
+	}
+}
+
+func currentEndpoints() Endpoints {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	return endpoints
+}
+
+// NewS3Client creates an S3 client for a region with the endpoint overrides
+// An empty region uses the region of the config
+func NewS3Client(cfg aws.Config, region string) *s3.Client {
+	e := currentEndpoints()
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if region != "" {
+			o.Region = region
+		}
+		if e.S3 != "" {
+			o.BaseEndpoint = aws.String(e.S3)
+		}
+		if e.S3PathStyle {
+			o.UsePathStyle = true
+		}
+	})
+}
+
+// NewTranscribeClient creates a Transcribe client for a region with the endpoint override
+// An empty region uses the region of the config
+func NewTranscribeClient(cfg aws.Config, region string) *transcribe.Client {
+	e := currentEndpoints()
+	return transcribe.NewFromConfig(cfg, func(o *transcribe.Options) {
+		if region != "" {
+			o.Region = region
+		}
+		if e.Transcribe != "" {
+			o.BaseEndpoint = aws.String(e.Transcribe)
+		}
+	})
+}
+
+// NewBedrockClient creates a Bedrock runtime client for a region with the endpoint override
+// An empty region uses the region of the config
+func NewBedrockClient(cfg aws.Config, region string) *bedrockruntime.Client {
+	e := currentEndpoints()
+	return bedrockruntime.NewFromConfig(cfg, func(o *bedrockruntime.Options) {
+		if region != "" {
+			o.Region = region
+		}
+		if e.Bedrock != "" {
+			o.BaseEndpoint = aws.String(e.Bedrock)
+		}
+	})
+}
+
+// NewBedrockControlClient creates a client of the Bedrock control plane for a region,
+// which lists the models, with the Bedrock control endpoint override. The runtime endpoint
+// does not serve the model listing. An empty region uses the region of the config
+func NewBedrockControlClient(cfg aws.Config, region string) *bedrock.Client {
+	e := currentEndpoints()
+	return bedrock.NewFromConfig(cfg, func(o *bedrock.Options) {
+		if region != "" {
+			o.Region = region
+		}
+		if e.BedrockControl != "" {
+			o.BaseEndpoint = aws.String(e.BedrockControl)
+		}
+	})
+}
+
+// NewSTSClient creates an STS client with the endpoint override
+func NewSTSClient(cfg aws.Config) *sts.Client {
+	e := currentEndpoints()
+	return sts.NewFromConfig(cfg, func(o *sts.Options) {
+		if e.STS != "" {
+			o.BaseEndpoint = aws.String(e.STS)
+		}
+	})
+}
+
+// credentialOptions applies the STS, SSO and SSO OIDC endpoint overrides to the clients the SDK
+// creates for the credentials of assume-role and SSO profiles. Roles with an MFA device
+// ask for the MFA code, the SDK takes only one set of assume-role options
+func credentialOptions(profile Profile, mfa bool) []func(*config.LoadOptions) error {
+	e := currentEndpoints()
+	return []func(*config.LoadOptions) error{
+		config.WithAssumeRoleCredentialOptions(func(o *stscreds.AssumeRoleOptions) {
+			if client, ok := o.Client.(*sts.Client); ok && e.STS != "" {
+				o.Client = sts.New(client.Options(), func(so *sts.Options) {
+					so.BaseEndpoint = aws.String(e.STS)
+				})
+			}
+			if mfa {
+				o.TokenProvider = mfaTokenProvider(profile)
+			}
+		}),
+		config.WithSSOProviderOptions(func(o *ssocreds.Options) {
+			if client, ok := o.Client.(*sso.Client); ok && e.SSO != "" {
+				o.Client = sso.New(client.Options(), func(so *sso.Options) {
+					so.BaseEndpoint = aws.String(e.SSO)
+				})
+			}
+		}),
+		config.WithSSOTokenProviderOptions(func(o *ssocreds.SSOTokenProviderOptions) {
+			if client, ok := o.Client.(*ssooidc.Client); ok && e.SSOOIDC != "" {
+				o.Client = ssooidc.New(client.Options(), func(so *ssooidc.Options) {
+					so.BaseEndpoint = aws.String(e.SSOOIDC)
+				})
+			}
+		}),
+	}
+}
+
+// newSSOOIDCClient creates the SSO OIDC client of the SSO login with the endpoint override
+// The OIDC operations are not signed, no credentials are needed
+func newSSOOIDCClient(region string) *ssooidc.Client {
+	e := currentEndpoints()
+	return ssooidc.New(ssooidc.Options{Region: region, HTTPClient: HTTPClient()}, func(o *ssooidc.Options) {
+		if e.SSOOIDC != "" {
+			o.BaseEndpoint = aws.String(e.SSOOIDC)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package aws
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// MFATokenHandler asks the user for the current code of the MFA device with the given serial,
+// e.g. in a dialog. It blocks until the code is entered, nil fails profiles that require MFA
+var MFATokenHandler func(profile, serial string) (string, error)
+
+// sessionCredentials keeps the credentials provider of each MFA profile,
+// so the session credentials are reused for their lifetime instead of asking for a new code.
+// The key holds profile name, role ARN and MFA serial, an edited profile gets a new provider
+var (
+	sessionCredentialsMu sync.Mutex
+	sessionCredentials   = map[string]aws.CredentialsProvider{}
+)
+
+// mfaTokenProvider lets the SDK ask for the MFA code when it assumes the role of the profile
+func mfaTokenProvider(profile Profile) func() (string, error) {
+	return func() (string, error) {
+		if MFATokenHandler == nil {
+			return "", fmt.Errorf("profile %s assumes role %s with MFA device %s, but no MFA code can be entered", profile.Name, profile.RoleARN, profile.MFASerial)
+		}
+		fmt.Printf("Profile %s requires an MFA code for %s\n", profile.Name, profile.MFASerial)
+		code, err := MFATokenHandler(profile.Name, profile.MFASerial)
+		if err != nil {
+			return "", fmt.Errorf("no MFA code for profile %s: %w", profile.Name, err)
+		}
+		return code, nil
+	}
+}
+
+// cachedSessionCredentials returns the credentials provider already in use for the profile,
+// or remembers provider for it. The SDK wraps it in a cache that refreshes on expiry
+func cachedSessionCredentials(profile Profile, provider aws.CredentialsProvider) aws.CredentialsProvider {
+	key := profile.Name + "|" + profile.RoleARN + "|" + profile.MFASerial
+	sessionCredentialsMu.Lock()
+	defer sessionCredentialsMu.Unlock()
+	if cached, ok := sessionCredentials[key]; ok {
+		return cached
+	}
+	sessionCredentials[key] = provider
+	return provider
+}
+
+// clearSessionCredentials forgets the session credentials of all MFA profiles
+func clearSessionCredentials() {
+	sessionCredentialsMu.Lock()
+	defer sessionCredentialsMu.Unlock()
+	clear(sessionCredentials)
+}
@@ -0,0 +1,137 @@
+package aws
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Network holds the proxy and TLS settings for all HTTP connections of the app
+type Network struct {
+	// ProxyURL is used for HTTP and HTTPS, empty uses HTTP_PROXY and HTTPS_PROXY of the environment
+	ProxyURL string
+	// NoProxy is a comma separated list of hosts, domains and CIDRs reached without proxy
+	NoProxy string
+	// CABundle is a PEM file with certificates trusted in addition to the system ones,
+	// e.g. the root certificate of a TLS-intercepting proxy
+	CABundle string
+}
+
+var (
+	networkMu  sync.Mutex
+	network    Network
+	httpClient *awshttp.BuildableClient
+)
+
+// Validate checks the proxy URL and loads the CA bundle
+func (n Network) Validate() error {
+	if n.ProxyURL != "" {
+		u, err := url.Parse(n.ProxyURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			return fmt.Errorf("proxy %q is not a http, https or socks5 URL", n.ProxyURL)
+		}
+	}
+	if n.CABundle != "" {
+		if _, err := loadCABundle(n.CABundle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetNetwork sets the proxy and TLS settings for all clients created afterwards
+// A change drops the current session and the MFA session credentials, so they are created again
+func SetNetwork(n Network) error {
+	n.ProxyURL = strings.TrimSpace(n.ProxyURL)
+	n.NoProxy = strings.TrimSpace(n.NoProxy)
+	n.CABundle = strings.TrimSpace(n.CABundle)
+	client, err := buildHTTPClient(n)
+	if err != nil {
+		return err
+	}
+
+	networkMu.Lock()
+	changed := n != network
+	network = n
+	httpClient = client
+	networkMu.Unlock()
+
+	if changed {
+		fmt.Printf("Network: proxy %q, no proxy %q, CA bundle %q\n", n.ProxyURL, n.NoProxy, n.CABundle)
+		sessionMu.Lock()
+		dropSession()
+		sessionMu.Unlock()
+	}
+	return nil
+}
+
+// HTTPClient returns the HTTP client with the proxy and CA bundle settings
+// It is used by the AWS SDK and should be used by any other HTTP based backend
+func HTTPClient() *awshttp.BuildableClient {
+	networkMu.Lock()
+	defer networkMu.Unlock()
+	if httpClient == nil {
+		// Without settings the environment decides about the proxy
+		httpClient, _ = buildHTTPClient(Network{})
+	}
+	return httpClient
+}
+
+// buildHTTPClient creates the SDK's HTTP client with the proxy and TLS settings
+func buildHTTPClient(n Network) (*awshttp.BuildableClient, error) {
+	var rootCAs *x509.CertPool
+	if n.CABundle != "" {
+		pool, err := loadCABundle(n.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		rootCAs = pool
+	}
+
+	// Proxy settings fall back to the environment, so an empty form keeps HTTP_PROXY working
+	proxyConfig := httpproxy.FromEnvironment()
+	if n.ProxyURL != "" {
+		proxyConfig.HTTPProxy = n.ProxyURL
+		proxyConfig.HTTPSProxy = n.ProxyURL
+	}
+	if n.NoProxy != "" {
+		proxyConfig.NoProxy = n.NoProxy
+	}
+	proxyFunc := proxyConfig.ProxyFunc()
+
+	return awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		tr.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+		if rootCAs != nil {
+			if tr.TLSClientConfig == nil {
+				tr.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+			}
+			tr.TLSClientConfig.RootCAs = rootCAs
+		}
+	}), nil
+}
+
+// loadCABundle returns the system certificates together with the certificates of a PEM file
+func loadCABundle(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", file)
+	}
+	return pool, nil
+}
@@ -0,0 +1,201 @@
+package aws
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Profile types, derived from the settings of a profile
+const (
+	ProfileStatic     = "static"
+	ProfileAssumeRole = "assume-role"
+	ProfileSSO        = "sso"
+	ProfileProcess    = "process"
+	ProfileOther      = "other"
+)
+
+// Profile is a named profile from the shared AWS config and credentials files
+type Profile struct {
+	Name   string
+	Region string
+	Type   string
+	// Assume-role settings, MFASerial is set if the role requires an MFA code
+	RoleARN   string
+	MFASerial string
+	// SSO settings, either from the profile or from its sso-session section
+	SSOSession  string
+	SSOStartURL string
+	SSORegion   string
+}
+
+// Label describes the profile for selection lists, e.g. "dev (assume-role with MFA, eu-central-1)"
+func (p Profile) Label() string {
+	profileType := p.Type
+	if p.MFASerial != "" {
+		profileType += " with MFA"
+	}
+	if p.Region == "" {
+		return fmt.Sprintf("%s (%s)", p.Name, profileType)
+	}
+	return fmt.Sprintf("%s (%s, %s)", p.Name, profileType, p.Region)
+}
+
+// ConfigFile returns the path of the shared AWS config file
+// AWS_CONFIG_FILE overrides the default ~/.aws/config
+func ConfigFile() string {
+	return sharedFile("AWS_CONFIG_FILE", "config")
+}
+
+// CredentialsFile returns the path of the shared AWS credentials file
+// AWS_SHARED_CREDENTIALS_FILE overrides the default ~/.aws/credentials
+func CredentialsFile() string {
+	return sharedFile("AWS_SHARED_CREDENTIALS_FILE", "credentials")
+}
+
+func sharedFile(envName, name string) string {
+	if file := os.Getenv(envName); file != "" {
+		return file
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".aws", name)
+}
+
+// ListProfiles returns the profiles of the shared AWS config and credentials files sorted by name
+// Missing files result in an empty list
+func ListProfiles() ([]Profile, error) {
+	configSections, err := readINI(ConfigFile())
+	if err != nil {
+		return nil, err
+	}
+	credentialSections, err := readINI(CredentialsFile())
+	if err != nil {
+		return nil, err
+	}
+
+	// Profiles in the config file are [default] or [profile name],
+	// sections like [sso-session name] are referenced by profiles
+	settings := map[string]map[string]string{}
+	ssoSessions := map[string]map[string]string{}
+	for section, values := range configSections {
+		if section == "default" {
+			settings[section] = values
+		} else if name, ok := strings.CutPrefix(section, "profile "); ok {
+			settings[strings.TrimSpace(name)] = values
+		} else if name, ok := strings.CutPrefix(section, "sso-session "); ok {
+			ssoSessions[strings.TrimSpace(name)] = values
+		}
+	}
+	// Profiles in the credentials file have no prefix
+	for name, values := range credentialSections {
+		merged := map[string]string{}
+		for key, value := range settings[name] {
+			merged[key] = value
+		}
+		for key, value := range values {
+			if _, ok := merged[key]; !ok {
+				merged[key] = value
+			}
+		}
+		settings[name] = merged
+	}
+
+	var profiles []Profile
+	for name, values := range settings {
+		profile := Profile{
+			Name:        name,
+			Region:      values["region"],
+			RoleARN:     values["role_arn"],
+			MFASerial:   values["mfa_serial"],
+			SSOSession:  values["sso_session"],
+			SSOStartURL: values["sso_start_url"],
+			SSORegion:   values["sso_region"],
+		}
+		if session, ok := ssoSessions[profile.SSOSession]; ok {
+			profile.SSOStartURL = session["sso_start_url"]
+			profile.SSORegion = session["sso_region"]
+		}
+		switch {
+		case profile.SSOSession != "" || profile.SSOStartURL != "":
+			profile.Type = ProfileSSO
+		case profile.RoleARN != "":
+			profile.Type = ProfileAssumeRole
+		case values["credential_process"] != "":
+			profile.Type = ProfileProcess
+		case values["aws_access_key_id"] != "":
+			profile.Type = ProfileStatic
+		default:
+			profile.Type = ProfileOther
+		}
+		profiles = append(profiles, profile)
+	}
+	slices.SortFunc(profiles, func(a, b Profile) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return profiles, nil
+}
+
+// FindProfile returns the profile with the given name from the shared AWS files
+func FindProfile(name string) (Profile, error) {
+	profiles, err := ListProfiles()
+	if err != nil {
+		return Profile{}, err
+	}
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return Profile{}, fmt.Errorf("profile %s not found in %s or %s", name, ConfigFile(), CredentialsFile())
+}
+
+// readINI reads the sections of a shared AWS file into a map of lower case keys and values
+// Indented lines belong to nested settings like "s3 =" and are skipped
+func readINI(path string) (map[string]map[string]string, error) {
+	sections := map[string]map[string]string{}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sections, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var current map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.Join(strings.Fields(strings.Trim(line, "[]")), " ")
+			current = sections[name]
+			if current == nil {
+				current = map[string]string{}
+				sections[name] = current
+			}
+			continue
+		}
+		if current == nil || raw[0] == ' ' || raw[0] == '\t' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		current[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return sections, nil
+}
@@ -0,0 +1,28 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// BucketRegion returns the region an S3 bucket is located in
+func BucketRegion(ctx context.Context, cfg aws.Config, bucket string) (string, error) {
+	s3Client := NewS3Client(cfg, "")
+	locationOutput, err := s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// An empty location constraint is the default region for GetBucketLocation
+	if locationOutput.LocationConstraint == "" {
+		return "us-east-1", nil
+	}
+	region := string(locationOutput.LocationConstraint)
+	fmt.Printf("Bucket %s is located in region %s\n", bucket, region)
+	return region, nil
+}
@@ -0,0 +1,175 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrock"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/transcribe"
+)
+
+// Session is the validated AWS config of a profile with the service clients built from it
+// Clients are created once per region and shared
+type Session struct {
+	Profile string
+	Config  aws.Config
+	Account string
+	ARN     string
+
+	mu                sync.Mutex
+	s3Clients         map[string]*s3.Client
+	transcribeClients map[string]*transcribe.Client
+	bedrockClients    map[string]*bedrockruntime.Client
+	controlClients    map[string]*bedrock.Client
+}
+
+// SessionChangedHandler is called after a new session is validated, e.g. to show the identity
+var SessionChangedHandler func(session *Session)
+
+var (
+	// sessionMu guards currentSession, it is never held while credentials are retrieved,
+	// which may wait for the MFA code or the SSO login in the UI
+	sessionMu      sync.Mutex
+	currentSession *Session
+	// sessionGeneration counts the dropped sessions, a session loaded meanwhile is not kept
+	sessionGeneration int
+	// loadMu serializes GetSession, so the MFA code or SSO login is asked for only once
+	loadMu sync.Mutex
+)
+
+// GetSession returns the session of the profile. The config is loaded and validated
+// on first use, after a profile change and when the credentials expired and cannot be refreshed
+func GetSession(ctx context.Context, profile string) (*Session, error) {
+	if profile == "" {
+		profile = "default"
+	}
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	sessionMu.Lock()
+	session := currentSession
+	sessionMu.Unlock()
+
+	if session != nil && session.Profile == profile {
+		// The credentials cache refreshes expired credentials, an error means a new session is needed
+		credentials, err := session.Config.Credentials.Retrieve(ctx)
+		if err == nil && !credentials.Expired() {
+			return session, nil
+		}
+		fmt.Printf("AWS credentials of profile %s expired, loading the profile again\n", profile)
+	}
+
+	sessionMu.Lock()
+	if currentSession == session {
+		dropSession()
+	}
+	generation := sessionGeneration
+	sessionMu.Unlock()
+
+	if err := loginIfRequired(profile); err != nil {
+		return nil, err
+	}
+	cfg, err := LoadAWSConfig(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	account, arn, err := GetCallerIdentity(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("AWS identity validated - Account: %s, User/Role: %s\n", account, arn)
+
+	session = &Session{
+		Profile:           profile,
+		Config:            cfg,
+		Account:           account,
+		ARN:               arn,
+		s3Clients:         map[string]*s3.Client{},
+		transcribeClients: map[string]*transcribe.Client{},
+		bedrockClients:    map[string]*bedrockruntime.Client{},
+		controlClients:    map[string]*bedrock.Client{},
+	}
+	sessionMu.Lock()
+	// Endpoints or network changed while loading, the next call loads the profile again
+	if generation == sessionGeneration {
+		currentSession = session
+	}
+	sessionMu.Unlock()
+
+	if SessionChangedHandler != nil {
+		SessionChangedHandler(session)
+	}
+	return session, nil
+}
+
+// dropSession forgets the current session and the cached MFA session credentials,
+// the next GetSession loads the profile again. sessionMu must be held
+func dropSession() {
+	currentSession = nil
+	sessionGeneration++
+	clearSessionCredentials()
+}
+
+// region returns the given region or the region of the profile if it is empty
+func (s *Session) region(region string) string {
+	if region == "" {
+		return s.Config.Region
+	}
+	return region
+}
+
+// S3 returns the S3 client for a region, empty uses the region of the profile
+func (s *Session) S3(region string) *s3.Client {
+	region = s.region(region)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client, ok := s.s3Clients[region]
+	if !ok {
+		client = NewS3Client(s.Config, region)
+		s.s3Clients[region] = client
+	}
+	return client
+}
+
+// Transcribe returns the Transcribe client for a region, empty uses the region of the profile
+func (s *Session) Transcribe(region string) *transcribe.Client {
+	region = s.region(region)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client, ok := s.transcribeClients[region]
+	if !ok {
+		client = NewTranscribeClient(s.Config, region)
+		s.transcribeClients[region] = client
+	}
+	return client
+}
+
+// Bedrock returns the Bedrock runtime client for a region, empty uses the region of the profile
+func (s *Session) Bedrock(region string) *bedrockruntime.Client {
+	region = s.region(region)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client, ok := s.bedrockClients[region]
+	if !ok {
+		client = NewBedrockClient(s.Config, region)
+		s.bedrockClients[region] = client
+	}
+	return client
+}
+
+// BedrockControl returns the Bedrock control plane client for a region, empty uses the region of the profile
+func (s *Session) BedrockControl(region string) *bedrock.Client {
+	region = s.region(region)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client, ok := s.controlClients[region]
+	if !ok {
+		client = NewBedrockControlClient(s.Config, region)
+		s.controlClients[region] = client
+	}
+	return client
+}
@@ -0,0 +1,190 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+)
+
+// deviceCodeGrant is the OAuth grant type of the SSO device authorization flow
+const deviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceAuthorization is shown to the user to approve an SSO login in the browser
+type DeviceAuthorization struct {
+	VerificationURI         string
+	VerificationURIComplete string
+	UserCode                string
+	ExpiresAt               time.Time
+}
+
+// ssoToken is the format of the token cache in ~/.aws/sso/cache shared with the AWS CLI and SDK
+type ssoToken struct {
+	AccessToken           string `json:"accessToken"`
+	ExpiresAt             string `json:"expiresAt"`
+	RefreshToken          string `json:"refreshToken,omitempty"`
+	ClientID              string `json:"clientId,omitempty"`
+	ClientSecret          string `json:"clientSecret,omitempty"`
+	RegistrationExpiresAt string `json:"registrationExpiresAt,omitempty"`
+	Region                string `json:"region,omitempty"`
+	StartURL              string `json:"startUrl,omitempty"`
+}
+
+// ssoCacheFile returns the token cache file of a profile
+// Profiles with an sso-session are keyed by the session name, legacy profiles by the start URL
+func ssoCacheFile(p Profile) (string, error) {
+	key := p.SSOStartURL
+	if p.SSOSession != "" {
+		key = p.SSOSession
+	}
+	return ssocreds.StandardCachedTokenFilepath(key)
+}
+
+// SSOLoginRequired reports whether an SSO profile has no valid cached token
+// A token of an sso-session that the SDK can still refresh counts as valid
+func SSOLoginRequired(p Profile) bool {
+	if p.Type != ProfileSSO {
+		return false
+	}
+	file, err := ssoCacheFile(p)
+	if err != nil {
+		return true
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return true
+	}
+	var token ssoToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return true
+	}
+
+	now := time.Now()
+	if expiresAt, err := time.Parse(time.RFC3339, token.ExpiresAt); err == nil && expiresAt.After(now.Add(time.Minute)) {
+		return false
+	}
+	if p.SSOSession != "" && token.RefreshToken != "" {
+		if registrationExpiresAt, err := time.Parse(time.RFC3339, token.RegistrationExpiresAt); err == nil && registrationExpiresAt.After(now) {
+			return false
+		}
+	}
+	return true
+}
+
+// SSOLogin runs the SSO device authorization flow for a profile and stores the token
+// in the cache the SDK and the AWS CLI read. prompt shows the verification URL and code
+// to the user, the login waits until it is approved in the browser, expires or ctx is cancelled
+func SSOLogin(ctx context.Context, p Profile, prompt func(DeviceAuthorization)) error {
+	if p.SSOStartURL == "" || p.SSORegion == "" {
+		return fmt.Errorf("profile %s has no sso_start_url and sso_region", p.Name)
+	}
+	file, err := ssoCacheFile(p)
+	if err != nil {
+		return err
+	}
+
+	client := newSSOOIDCClient(p.SSORegion)
+
+	registerInput := &ssooidc.RegisterClientInput{
+		ClientName: aws.String("audionote"),
+		ClientType: aws.String("public"),
+	}
+	if p.SSOSession != "" {
+		registerInput.Scopes = []string{"sso:account:access"}
+	}
+	registration, err := client.RegisterClient(ctx, registerInput)
+	if err != nil {
+		return fmt.Errorf("failed to register SSO client: %w", err)
+	}
+
+	authorization, err := client.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     registration.ClientId,
+		ClientSecret: registration.ClientSecret,
+		StartUrl:     aws.String(p.SSOStartURL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start SSO device authorization: %w", err)
+	}
+	expiresAt := time.Now().Add(time.Duration(authorization.ExpiresIn) * time.Second)
+	fmt.Printf("SSO login for profile %s: open %s and enter code %s\n",
+		p.Name, aws.ToString(authorization.VerificationUri), aws.ToString(authorization.UserCode))
+	prompt(DeviceAuthorization{
+		VerificationURI:         aws.ToString(authorization.VerificationUri),
+		VerificationURIComplete: aws.ToString(authorization.VerificationUriComplete),
+		UserCode:                aws.ToString(authorization.UserCode),
+		ExpiresAt:               expiresAt,
+	})
+
+	interval := time.Duration(max(authorization.Interval, 1)) * time.Second
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		if time.Now().After(expiresAt) {
+			return fmt.Errorf("SSO login for profile %s expired before it was approved", p.Name)
+		}
+
+		tokenOutput, err := client.CreateToken(ctx, &ssooidc.CreateTokenInput{
+			ClientId:     registration.ClientId,
+			ClientSecret: registration.ClientSecret,
+			DeviceCode:   authorization.DeviceCode,
+			GrantType:    aws.String(deviceCodeGrant),
+		})
+		if err != nil {
+			var pending *types.AuthorizationPendingException
+			if errors.As(err, &pending) {
+				continue
+			}
+			var slowDown *types.SlowDownException
+			if errors.As(err, &slowDown) {
+				interval += 5 * time.Second
+				continue
+			}
+			return fmt.Errorf("SSO login for profile %s failed: %w", p.Name, err)
+		}
+
+		token := ssoToken{
+			AccessToken: aws.ToString(tokenOutput.AccessToken),
+			ExpiresAt:   time.Now().Add(time.Duration(tokenOutput.ExpiresIn) * time.Second).UTC().Format(time.RFC3339),
+			Region:      p.SSORegion,
+			StartURL:    p.SSOStartURL,
+		}
+		// The SDK refreshes session tokens with the client registration
+		if p.SSOSession != "" {
+			token.RefreshToken = aws.ToString(tokenOutput.RefreshToken)
+			token.ClientID = aws.ToString(registration.ClientId)
+			token.ClientSecret = aws.ToString(registration.ClientSecret)
+			token.RegistrationExpiresAt = time.Unix(registration.ClientSecretExpiresAt, 0).UTC().Format(time.RFC3339)
+		}
+		if err := writeSSOToken(file, token); err != nil {
+			return err
+		}
+		fmt.Printf("SSO login for profile %s succeeded, token valid until %s\n", p.Name, token.ExpiresAt)
+		return nil
+	}
+}
+
+// writeSSOToken stores a token in the SSO cache, readable only by the user
+func writeSSOToken(file string, token ssoToken) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return fmt.Errorf("failed to create SSO cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return fmt.Errorf("failed to write SSO token cache %s: %w", file, err)
+	}
+	return nil
+}
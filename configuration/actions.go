@@ -0,0 +1,352 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// CleanActionName turns text into an action name: lowercase letters, digits and hyphens
+func CleanActionName(text string) string {
+	cleanText := strings.ToLower(strings.ReplaceAll(text, " ", "-"))
+	var result strings.Builder
+	for _, char := range cleanText {
+		if (char >= 'a' && char <= 'z') || (char >= '0' && char <= '9') || char == '-' {
+			result.WriteRune(char)
+		}
+	}
+	return result.String()
+}
+
+// checkNewAction checks that name is a valid action name without a prompt in any layer
+func checkNewAction(name string) error {
+	if name == "" {
+		return errors.New("action name cannot be empty")
+	}
+	if CleanActionName(name) != name {
+		return fmt.Errorf("action name %q may only contain lowercase letters, digits and hyphens", name)
+	}
+	if entry, err := FindPrompt(name); err == nil {
+		return fmt.Errorf("action %q already exists as %s prompt", name, entry.Source)
+	}
+	return nil
+}
+
+// checkPersonal checks that the prompt of an action is a personal prompt of its own
+func checkPersonal(action, operation string) (PromptEntry, error) {
+	entry, err := FindPrompt(action)
+	if err != nil {
+		return entry, err
+	}
+	if entry.Source.ReadOnly() {
+		return entry, fmt.Errorf("%s is a read-only %s prompt and cannot be %s", action, entry.Source, operation)
+	}
+	return entry, nil
+}
+
+// OrderActions returns the actions in display order: the configured order, then by name.
+// Actions of a group are kept together, groups follow the position of their first action
+func (c *Config) OrderActions(actions []string) []string {
+	ordered := slices.Clone(actions)
+	position := func(action string) int {
+		if i := slices.Index(c.ActionOrder, action); i >= 0 {
+			return i
+		}
+		return len(c.ActionOrder)
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		pi, pj := position(ordered[i]), position(ordered[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return ordered[i] < ordered[j]
+	})
+
+	var result []string
+	for _, block := range c.groupBlocks(ordered) {
+		result = append(result, block...)
+	}
+	return result
+}
+
+// groupBlocks splits ordered actions by group, in order of the first action of each group
+func (c *Config) groupBlocks(ordered []string) [][]string {
+	var groups []string
+	blocks := map[string][]string{}
+	for _, action := range ordered {
+		group := c.ActionGroups[action]
+		if _, ok := blocks[group]; !ok {
+			groups = append(groups, group)
+		}
+		blocks[group] = append(blocks[group], action)
+	}
+	var result [][]string
+	for _, group := range groups {
+		result = append(result, blocks[group])
+	}
+	return result
+}
+
+// ActionLabels returns the names of actions in the action selector, e.g. "Writing / blog [team]"
+// Team and personal prompts are marked, built-in prompts are not
+func (c *Config) ActionLabels(actions []string) map[string]string {
+	sources := map[string]PromptSource{}
+	entries, _ := ListPrompts()
+	for _, entry := range entries {
+		sources[entry.Action] = entry.Source
+	}
+	labels := map[string]string{}
+	for _, action := range actions {
+		label := action
+		if group := c.ActionGroups[action]; group != "" {
+			label = group + " / " + action
+		}
+		if source, ok := sources[action]; ok && source != SourceBuiltIn {
+			label += " [" + string(source) + "]"
+		}
+		labels[action] = label
+	}
+	return labels
+}
+
+// Groups returns the names of the action groups, sorted
+func (c *Config) Groups() []string {
+	var groups []string
+	for _, group := range c.ActionGroups {
+		if !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// SetActionGroup puts an action in a group, an empty group removes it from its group
+func (c *Config) SetActionGroup(action, group string) {
+	group = strings.TrimSpace(group)
+	if group == "" {
+		delete(c.ActionGroups, action)
+		return
+	}
+	if c.ActionGroups == nil {
+		c.ActionGroups = map[string]string{}
+	}
+	c.ActionGroups[action] = group
+}
+
+// MoveAction moves an action one place up or down in the display order of actions.
+// At the edge of its group the whole group moves past the neighbouring group
+func (c *Config) MoveAction(actions []string, action string, up bool) {
+	ordered := c.OrderActions(actions)
+	i := slices.Index(ordered, action)
+	j := i + 1
+	if up {
+		j = i - 1
+	}
+	if i < 0 || j < 0 || j >= len(ordered) {
+		return
+	}
+
+	if c.ActionGroups[ordered[j]] == c.ActionGroups[action] {
+		ordered[i], ordered[j] = ordered[j], ordered[i]
+		c.ActionOrder = ordered
+		return
+	}
+
+	blocks := c.groupBlocks(ordered)
+	bi := slices.IndexFunc(blocks, func(block []string) bool { return slices.Contains(block, action) })
+	bj := bi + 1
+	if up {
+		bj = bi - 1
+	}
+	blocks[bi], blocks[bj] = blocks[bj], blocks[bi]
+	c.ActionOrder = nil
+	for _, block := range blocks {
+		c.ActionOrder = append(c.ActionOrder, block...)
+	}
+}
+
+// RenameAction renames the prompt file and history of an action and moves its settings
+func (c *Config) RenameAction(action, name string) error {
+	entry, err := checkPersonal(action, "renamed")
+	if err != nil {
+		return err
+	}
+	if len(entry.Overrides) > 0 {
+		return fmt.Errorf("%s overrides a %s prompt of the same name, duplicate it instead", action, entry.Overrides[0])
+	}
+	if err := checkNewAction(name); err != nil {
+		return err
+	}
+	err = os.Rename(PromptFile(action), PromptFile(name))
+	InvalidatePrompts()
+	if err != nil {
+		return fmt.Errorf("failed to rename prompt file of %s: %v", action, err)
+	}
+	if err := os.Rename(HistoryDir(action), HistoryDir(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Printf("Warning: history of %s not moved: %v\n", action, err)
+	}
+	fmt.Printf("Renamed action %s to %s\n", action, name)
+
+	if inference, ok := c.ActionInference[action]; ok {
+		c.ActionInference[name] = inference
+		delete(c.ActionInference, action)
+	}
+	if inputs, ok := c.PromptInputs[action]; ok {
+		c.PromptInputs[name] = inputs
+		delete(c.PromptInputs, action)
+	}
+	if group, ok := c.ActionGroups[action]; ok {
+		c.ActionGroups[name] = group
+		delete(c.ActionGroups, action)
+	}
+	if i := slices.Index(c.ActionOrder, action); i >= 0 {
+		c.ActionOrder[i] = name
+	}
+	if c.LastActionType == action {
+		c.LastActionType = name
+	}
+	return nil
+}
+
+// DuplicateAction copies the prompt and settings of an action to a new action
+// The copy is placed after the original
+func (c *Config) DuplicateAction(action, name string) error {
+	if err := checkNewAction(name); err != nil {
+		return err
+	}
+	content, err := LoadPromptContent(action)
+	if err != nil {
+		return err
+	}
+	if err := SavePromptContent(name, content); err != nil {
+		return err
+	}
+	fmt.Printf("Duplicated action %s as %s\n", action, name)
+
+	if inference, ok := c.ActionInference[action]; ok {
+		c.ActionInference[name] = inference
+	}
+	if inputs, ok := c.PromptInputs[action]; ok {
+		c.PromptInputs[name] = inputs
+	}
+	if group, ok := c.ActionGroups[action]; ok {
+		c.ActionGroups[name] = group
+	}
+	if i := slices.Index(c.ActionOrder, action); i >= 0 {
+		c.ActionOrder = slices.Insert(c.ActionOrder, i+1, name)
+	}
+	return nil
+}
+
+// ForkPrompt copies a built-in or team prompt to a personal prompt. The same name overrides
+// the original, a new name creates a new action with the settings of the original
+func (c *Config) ForkPrompt(action, name string) error {
+	entry, err := FindPrompt(action)
+	if err != nil {
+		return err
+	}
+	if !entry.Source.ReadOnly() {
+		return fmt.Errorf("%s is already a personal prompt", action)
+	}
+	if name != action {
+		return c.DuplicateAction(action, name)
+	}
+	content, err := LoadPromptContent(action)
+	if err != nil {
+		return err
+	}
+	if err := SavePromptContent(action, content); err != nil {
+		return err
+	}
+	fmt.Printf("Forked %s prompt %s to personal\n", entry.Source, action)
+	return nil
+}
+
+// DeletedAction holds a deleted action to undo the delete
+type DeletedAction struct {
+	Action    string
+	Content   string
+	Inference *Inference
+	Inputs    map[string]string
+	Group     string
+	Position  int
+	// Reverted is set when the deleted prompt overrode a built-in or team prompt,
+	// the action and its settings stay
+	Reverted bool
+}
+
+// DeleteAction removes the prompt file and settings of an action
+// The prompt stays in the history, the returned action restores it with RestoreAction.
+// Deleting a personal copy of a built-in or team prompt reverts the action to that prompt
+func (c *Config) DeleteAction(action string) (*DeletedAction, error) {
+	entry, err := checkPersonal(action, "deleted")
+	if err != nil {
+		return nil, err
+	}
+	content, err := LoadPromptContent(action)
+	if err != nil {
+		return nil, err
+	}
+	if err := removePersonalPrompt(action, content); err != nil {
+		return nil, err
+	}
+	if len(entry.Overrides) > 0 {
+		fmt.Printf("Deleted personal prompt of %s, the %s prompt is used again\n", action, entry.Overrides[0])
+		return &DeletedAction{Action: action, Content: content, Position: -1, Reverted: true}, nil
+	}
+	fmt.Printf("Deleted action %s\n", action)
+
+	deleted := &DeletedAction{
+		Action:   action,
+		Content:  content,
+		Inputs:   c.PromptInputs[action],
+		Group:    c.ActionGroups[action],
+		Position: slices.Index(c.ActionOrder, action),
+	}
+	if inference, ok := c.ActionInference[action]; ok {
+		deleted.Inference = &inference
+	}
+	delete(c.ActionInference, action)
+	delete(c.PromptInputs, action)
+	delete(c.ActionGroups, action)
+	if deleted.Position >= 0 {
+		c.ActionOrder = slices.Delete(c.ActionOrder, deleted.Position, deleted.Position+1)
+	}
+	return deleted, nil
+}
+
+// RestoreAction undoes DeleteAction
+func (c *Config) RestoreAction(deleted *DeletedAction) error {
+	if _, err := os.Stat(PromptFile(deleted.Action)); err == nil {
+		return fmt.Errorf("action %q already exists", deleted.Action)
+	}
+	if !deleted.Reverted {
+		if err := checkNewAction(deleted.Action); err != nil {
+			return err
+		}
+	}
+	if err := SavePromptContent(deleted.Action, deleted.Content); err != nil {
+		return err
+	}
+	fmt.Printf("Restored action %s\n", deleted.Action)
+	if deleted.Reverted {
+		return nil
+	}
+
+	if deleted.Inference != nil {
+		c.SetActionInference(deleted.Action, *deleted.Inference)
+	}
+	if deleted.Inputs != nil {
+		c.SetPromptInputs(deleted.Action, deleted.Inputs)
+	}
+	c.SetActionGroup(deleted.Action, deleted.Group)
+	if deleted.Position >= 0 {
+		c.ActionOrder = slices.Insert(c.ActionOrder, min(deleted.Position, len(c.ActionOrder)), deleted.Action)
+	}
+	return nil
+}
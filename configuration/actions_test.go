@@ -0,0 +1,60 @@
+package configuration
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOrderActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []string
+		order   []string
+		groups  map[string]string
+		want    []string
+	}{
+		{name: "by name", actions: []string{"c", "a", "b"}, want: []string{"a", "b", "c"}},
+		{name: "configured order first", actions: []string{"a", "b", "c", "d"}, order: []string{"c", "a"}, want: []string{"c", "a", "b", "d"}},
+		{name: "unknown action in order", actions: []string{"a", "b"}, order: []string{"x", "b"}, want: []string{"b", "a"}},
+		{name: "group kept together", actions: []string{"a", "b", "c", "d"}, groups: map[string]string{"a": "g", "d": "g"}, want: []string{"a", "d", "b", "c"}},
+		{name: "group at its first action", actions: []string{"a", "b", "c", "d"}, order: []string{"c", "a", "b", "d"}, groups: map[string]string{"c": "x", "d": "x"}, want: []string{"c", "d", "a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{ActionOrder: tt.order, ActionGroups: tt.groups}
+			if got := c.OrderActions(tt.actions); !slices.Equal(got, tt.want) {
+				t.Errorf("OrderActions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoveAction(t *testing.T) {
+	actions := []string{"a", "b", "c"}
+	tests := []struct {
+		name   string
+		groups map[string]string
+		action string
+		up     bool
+		want   []string
+	}{
+		{name: "down", action: "a", want: []string{"b", "a", "c"}},
+		{name: "up", action: "c", up: true, want: []string{"a", "c", "b"}},
+		{name: "first up", action: "a", up: true, want: []string{"a", "b", "c"}},
+		{name: "last down", action: "c", want: []string{"a", "b", "c"}},
+		{name: "unknown action", action: "x", want: []string{"a", "b", "c"}},
+		{name: "within group", groups: map[string]string{"a": "g", "b": "g"}, action: "a", want: []string{"b", "a", "c"}},
+		{name: "group past next group", groups: map[string]string{"a": "g", "b": "g"}, action: "b", want: []string{"c", "a", "b"}},
+		{name: "past previous group", groups: map[string]string{"a": "g", "b": "g"}, action: "c", up: true, want: []string{"c", "a", "b"}},
+		{name: "first group up", groups: map[string]string{"a": "g", "b": "g"}, action: "a", up: true, want: []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{ActionGroups: tt.groups}
+			c.MoveAction(actions, tt.action, tt.up)
+			if got := c.OrderActions(actions); !slices.Equal(got, tt.want) {
+				t.Errorf("order after MoveAction = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
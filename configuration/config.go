@@ -3,6 +3,7 @@ package configuration
 import (
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
@@ -20,36 +21,51 @@ type Config struct {
 	LastLanguage   string `mapstructure:"last_language"`
 	LastDirectory  string `mapstructure:"last_directory"`
 	S3Bucket       string `mapstructure:"s3_bucket"`
+	S3BucketRegion string `mapstructure:"s3_bucket_region"`
+	S3Prefix       string `mapstructure:"s3_prefix"`
+	S3SSE          string `mapstructure:"s3_sse"`
+	S3KMSKeyID     string `mapstructure:"s3_kms_key_id"`
 	AWSProfile     string `mapstructure:"aws_profile"`
 	Model          string `mapstructure:"model"`
+	BedrockRegion  string `mapstructure:"bedrock_region"`
 	OutputLines    int    `mapstructure:"output_lines"`
 	OutputPath     string `mapstructure:"output_path"`
+	// S3Retention is one of "delete", "days" or "forever"
+	S3Retention     string `mapstructure:"s3_retention"`
+	S3RetentionDays int    `mapstructure:"s3_retention_days"`
+	// Endpoint overrides, empty uses the default endpoint of the region
+	EndpointS3             string `mapstructure:"endpoint_s3"`
+	EndpointTranscribe     string `mapstructure:"endpoint_transcribe"`
+	EndpointSTS            string `mapstructure:"endpoint_sts"`
+	EndpointBedrock        string `mapstructure:"endpoint_bedrock"`
+	EndpointBedrockControl string `mapstructure:"endpoint_bedrock_control"`
+	EndpointSSO            string `mapstructure:"endpoint_sso"`
+	EndpointSSOOIDC        string `mapstructure:"endpoint_sso_oidc"`
+	S3PathStyle            bool   `mapstructure:"s3_path_style"`
+	// Network settings, an empty proxy uses HTTP_PROXY and HTTPS_PROXY of the environment
+	ProxyURL string `mapstructure:"proxy_url"`
+	NoProxy  string `mapstructure:"no_proxy"`
+	CABundle string `mapstructure:"ca_bundle"`
+	// Inference parameters for all actions, ActionInference overrides them per action
+	Inference       Inference            `mapstructure:"inference"`
+	ActionInference map[string]Inference `mapstructure:"action_inference"`
+	// PromptInputs are the last values entered for the prompt inputs of each action
+	PromptInputs map[string]map[string]string `mapstructure:"prompt_inputs"`
+	// Team prompts are read-only, between the built-in and the personal prompts.
+	// TeamPromptSource is "directory" for TeamPromptDir or "s3" for TeamPromptS3Prefix in S3Bucket
+	TeamPromptSource   string `mapstructure:"team_prompt_source"`
+	TeamPromptDir      string `mapstructure:"team_prompt_dir"`
+	TeamPromptS3Prefix string `mapstructure:"team_prompt_s3_prefix"`
+	// ActionOrder is the display order of the actions, unlisted actions follow by name
+	ActionOrder []string `mapstructure:"action_order"`
+	// ActionGroups maps an action to its group in the action selector
+	ActionGroups map[string]string `mapstructure:"action_groups"`
+	// FirstRun is set when the config file was just created, it is not saved
+	FirstRun bool `mapstructure:"-"`
 }
 
 var ConfigPath string
 
-// LoadPromptFiles reads all prompt-*.txt files from the config directory
-func LoadPromptFiles() ([]string, error) {
-	var actionTypes []string
-
-	err := filepath.WalkDir("./config", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !d.IsDir() && strings.HasPrefix(d.Name(), "prompt-") && strings.HasSuffix(d.Name(), ".txt") {
-			// Extract action type from filename: prompt-ACTION.txt -> ACTION
-			actionType := strings.TrimPrefix(d.Name(), "prompt-")
-			actionType = strings.TrimSuffix(actionType, ".txt")
-			actionTypes = append(actionTypes, actionType)
-		}
-
-		return nil
-	})
-
-	return actionTypes, err
-}
-
 // InitConfigWithFS initializes Viper configuration with embedded filesystem and returns a Config struct
 func InitConfigWithFS(defaultConfigFS fs.FS) *Config {
 	homeDir, err := os.UserHomeDir()
@@ -69,19 +85,45 @@ func InitConfigWithFS(defaultConfigFS fs.FS) *Config {
 	viper.SetDefault("last_language", "en-US")
 	viper.SetDefault("last_directory", documentsDir)
 	viper.SetDefault("s3_bucket", "")
+	viper.SetDefault("s3_bucket_region", "")
+	viper.SetDefault("s3_prefix", "summary/")
+	viper.SetDefault("s3_sse", "")
+	viper.SetDefault("s3_kms_key_id", "")
 	viper.SetDefault("aws_profile", "default")
 	viper.SetDefault("model", "anthropic.claude-3-5-sonnet-20240620-v1:0")
+	viper.SetDefault("bedrock_region", "")
 	viper.SetDefault("output_lines", 10)
 	viper.SetDefault("output_path", filepath.Join(documentsDir, "result.txt"))
+	viper.SetDefault("s3_retention", "forever")
+	viper.SetDefault("s3_retention_days", 7)
+	viper.SetDefault("endpoint_s3", "")
+	viper.SetDefault("endpoint_transcribe", "")
+	viper.SetDefault("endpoint_sts", "")
+	viper.SetDefault("endpoint_bedrock", "")
+	viper.SetDefault("endpoint_sso", "")
+	viper.SetDefault("endpoint_bedrock_control", "")
+	viper.SetDefault("endpoint_sso_oidc", "")
+	viper.SetDefault("s3_path_style", false)
+	viper.SetDefault("proxy_url", "")
+	viper.SetDefault("no_proxy", "")
+	viper.SetDefault("ca_bundle", "")
+	viper.SetDefault("team_prompt_source", "")
+	viper.SetDefault("team_prompt_dir", "")
+	viper.SetDefault("team_prompt_s3_prefix", "")
 
 	// Try to read existing config
+	firstRun := false
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			// Config file not found, create it
 			fmt.Println("Config file not found, creating new one...")
 
-			CreateConfigFile(defaultConfigFS)
-
+			if err := CreateConfigFile(defaultConfigFS); err != nil {
+				fmt.Printf("Error creating config file: %v\n", err)
+			} else if err := viper.ReadInConfig(); err != nil {
+				fmt.Printf("Error reading config file: %v\n", err)
+			}
+			firstRun = true
 		} else {
 			fmt.Printf("Error reading config file: %v\n", err)
 		}
@@ -91,12 +133,43 @@ func InitConfigWithFS(defaultConfigFS fs.FS) *Config {
 	if err := viper.Unmarshal(&config); err != nil {
 		fmt.Printf("Error unmarshaling config: %v\n", err)
 	}
+	config.FirstRun = firstRun
+
+	// Prompts are layered: built-in, team and personal
+	SetBuiltInPrompts(defaultConfigFS)
+	// A directory without source is from a version that only knew team directories
+	if config.TeamPromptSource == TeamSourceNone && config.TeamPromptDir != "" {
+		config.TeamPromptSource = TeamSourceDirectory
+	}
+	config.SetTeamPrompts(config.TeamPromptSource, config.TeamPromptDir, config.TeamPromptS3Prefix)
 
 	// Ensure last directory exists, fallback to Documents if not
 	if config.LastDirectory == "" || !DirExists(config.LastDirectory) {
 		config.LastDirectory = documentsDir
 	}
 
+	// Validate retention, unknown values keep all objects
+	switch config.S3Retention {
+	case "delete", "days", "forever":
+	default:
+		config.S3Retention = "forever"
+	}
+	if config.S3RetentionDays < 1 {
+		config.S3RetentionDays = 7
+	}
+
+	// Invalid inference parameters would fail every model request
+	if err := config.Inference.Validate(); err != nil {
+		fmt.Printf("Ignoring inference parameters: %v\n", err)
+		config.Inference = Inference{}
+	}
+	for action, inference := range config.ActionInference {
+		if err := inference.Validate(); err != nil {
+			fmt.Printf("Ignoring inference parameters of action %s: %v\n", action, err)
+			delete(config.ActionInference, action)
+		}
+	}
+
 	// Validate OutputLines - ensure it's between 5 and 50
 	if config.OutputLines < 5 {
 		config.OutputLines = 10
@@ -113,18 +186,85 @@ func (c *Config) Save() {
 	viper.Set("last_language", c.LastLanguage)
 	viper.Set("last_directory", c.LastDirectory)
 	viper.Set("s3_bucket", c.S3Bucket)
+	viper.Set("s3_bucket_region", c.S3BucketRegion)
+	viper.Set("s3_prefix", c.S3Prefix)
+	viper.Set("s3_sse", c.S3SSE)
+	viper.Set("s3_kms_key_id", c.S3KMSKeyID)
 	viper.Set("aws_profile", c.AWSProfile)
 	viper.Set("model", c.Model)
+	viper.Set("bedrock_region", c.BedrockRegion)
 	viper.Set("output_lines", c.OutputLines)
 	viper.Set("output_path", c.OutputPath)
-
-	if err := viper.WriteConfigAs(path.Join(ConfigPath, "config.yaml")); err != nil {
+	viper.Set("s3_retention", c.S3Retention)
+	viper.Set("s3_retention_days", c.S3RetentionDays)
+	viper.Set("endpoint_s3", c.EndpointS3)
+	viper.Set("endpoint_transcribe", c.EndpointTranscribe)
+	viper.Set("endpoint_sts", c.EndpointSTS)
+	viper.Set("endpoint_bedrock", c.EndpointBedrock)
+	viper.Set("endpoint_bedrock_control", c.EndpointBedrockControl)
+	viper.Set("endpoint_sso", c.EndpointSSO)
+	viper.Set("endpoint_sso_oidc", c.EndpointSSOOIDC)
+	viper.Set("s3_path_style", c.S3PathStyle)
+	viper.Set("proxy_url", c.ProxyURL)
+	viper.Set("no_proxy", c.NoProxy)
+	viper.Set("ca_bundle", c.CABundle)
+	viper.Set("team_prompt_source", c.TeamPromptSource)
+	viper.Set("team_prompt_dir", c.TeamPromptDir)
+	viper.Set("team_prompt_s3_prefix", c.TeamPromptS3Prefix)
+
+	if err := writeConfig(path.Join(ConfigPath, "config.yaml"), c.replacedSettings()); err != nil {
 		fmt.Printf("Error writing config file: %v\n", err)
 	} else {
 		fmt.Println("Configuration saved successfully")
 	}
 }
 
+// replacedSettings returns the map and list settings, they are written as a whole on save
+func (c *Config) replacedSettings() map[string]any {
+	actionInference := map[string]any{}
+	for action, inference := range c.ActionInference {
+		if !inference.IsZero() {
+			actionInference[action] = inference.settings()
+		}
+	}
+	promptInputs := map[string]any{}
+	for action, values := range c.PromptInputs {
+		promptInputs[action] = values
+	}
+	actionGroups := map[string]any{}
+	for action, group := range c.ActionGroups {
+		actionGroups[action] = group
+	}
+	return map[string]any{
+		"inference":        c.Inference.settings(),
+		"action_inference": actionInference,
+		"prompt_inputs":    promptInputs,
+		"action_order":     c.ActionOrder,
+		"action_groups":    actionGroups,
+	}
+}
+
+// writeConfig writes the viper settings to file with the replaced settings instead of their
+// current values. Viper merges maps with the values read from the file, so removed entries would
+// be written again. A fresh instance holds only the settings to write
+func writeConfig(file string, replaced map[string]any) error {
+	settings := viper.AllSettings()
+	maps.Copy(settings, replaced)
+	out := viper.New()
+	for key, value := range settings {
+		out.Set(key, value)
+	}
+	return out.WriteConfigAs(file)
+}
+
+// BucketRegion returns the cached region of a bucket, empty if it is unknown
+func (c *Config) BucketRegion(bucket string) string {
+	if bucket != c.S3Bucket {
+		return ""
+	}
+	return c.S3BucketRegion
+}
+
 // GetDirectoryURI returns a URI for the directory, with enhanced compatibility
 func (c *Config) GetDirectoryURI() fyne.URI {
 	if c.LastDirectory != "" && DirExists(c.LastDirectory) {
@@ -210,43 +350,13 @@ func (c *Config) TestDirectoryAccess() bool {
 	return true
 }
 
-// LoadPromptContent reads the content of a prompt file for the given action type
-func LoadPromptContent(actionType string) (string, error) {
-	filename := fmt.Sprintf("prompt-%s.txt", actionType)
-	filepath := filepath.Join(ConfigPath, filename)
-	fmt.Printf("Loading prompt file %s\n", filename)
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read prompt file %s: %v", filename, err)
-	}
-
-	return string(content), nil
-}
-
-// SavePromptContent saves the content to a prompt file for the given action type
-func SavePromptContent(actionType, content string) error {
-	filename := fmt.Sprintf("prompt-%s.txt", actionType)
-	filepath := filepath.Join(ConfigPath, filename)
-
-	// Ensure config directory exists
-	if err := os.MkdirAll("./config", 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %v", err)
-	}
-
-	err := os.WriteFile(filepath, []byte(content), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write prompt file %s: %v", filename, err)
-	}
-
-	return nil
-}
-
 // Contains checks if a slice contains a string
 func Contains(slice []string, item string) bool {
 	return slices.Contains(slice, item)
 }
 
-// First time config
+// CreateConfigFile sets up the config directory on the first run
+// The default config leaves AWS profile, bucket and model to the setup wizard
 func CreateConfigFile(defaultConfigFS fs.FS) error {
 
 	// Ensure config directory exists
@@ -266,6 +376,15 @@ func CreateConfigFile(defaultConfigFS fs.FS) error {
 			return nil
 		}
 
+		// Prompts and partials stay built-in, so new versions of the app update them
+		name := d.Name()
+		if d.IsDir() && name == "partials" {
+			return fs.SkipDir
+		}
+		if name == "manifest.yaml" || (strings.HasPrefix(name, "prompt-") && strings.HasSuffix(name, ".txt")) {
+			return nil
+		}
+
 		// Get the relative path within config-default
 		relPath, err := filepath.Rel("config-default", path)
 		if err != nil {
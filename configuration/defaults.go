@@ -0,0 +1,216 @@
+package configuration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultsManifest versions the built-in prompts, it is config-default/manifest.yaml
+type DefaultsManifest struct {
+	// Version increases with every change of the built-in prompts
+	Version int                      `yaml:"version"`
+	Prompts map[string]DefaultPrompt `yaml:"prompts"`
+}
+
+// DefaultPrompt is the manifest entry of a built-in prompt
+type DefaultPrompt struct {
+	// Version of the prompt, unlisted prompts are at version 1
+	Version int `yaml:"version"`
+	// Previous holds the SHA-256 of earlier versions, to tell unchanged copies from edited ones
+	Previous []string `yaml:"previous"`
+}
+
+// defaultsState records which defaults the user has seen, it is ~/.config/audionote/defaults.yaml
+type defaultsState struct {
+	Version int `yaml:"version"`
+	// Prompts maps an action to the version of the built-in prompt the user has seen
+	Prompts map[string]int `yaml:"prompts"`
+}
+
+// DefaultUpdate is a changed built-in prompt whose personal copy was edited
+type DefaultUpdate struct {
+	Action  string
+	Version int
+	// Personal is the content of the personal copy, Default the new built-in prompt
+	Personal string
+	Default  string
+}
+
+// defaultsStateFile returns the file with the defaults the user has seen
+func defaultsStateFile() string {
+	return filepath.Join(ConfigPath, "defaults.yaml")
+}
+
+// LoadDefaultsManifest reads the manifest of the built-in prompts
+func LoadDefaultsManifest() (DefaultsManifest, error) {
+	var manifest DefaultsManifest
+	if builtInPrompts == nil {
+		return manifest, errors.New("no built-in prompts")
+	}
+	content, err := fs.ReadFile(builtInPrompts, "manifest.yaml")
+	if err != nil {
+		return manifest, fmt.Errorf("failed to read defaults manifest: %v", err)
+	}
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return manifest, fmt.Errorf("invalid defaults manifest: %v", err)
+	}
+	return manifest, nil
+}
+
+// promptVersion returns the version of a built-in prompt
+func (m DefaultsManifest) promptVersion(actionType string) int {
+	if prompt, ok := m.Prompts[actionType]; ok && prompt.Version > 0 {
+		return prompt.Version
+	}
+	return 1
+}
+
+func loadDefaultsState() defaultsState {
+	state := defaultsState{Prompts: map[string]int{}}
+	content, err := os.ReadFile(defaultsStateFile())
+	if err != nil {
+		return state
+	}
+	if err := yaml.Unmarshal(content, &state); err != nil {
+		fmt.Printf("Ignoring invalid %s: %v\n", defaultsStateFile(), err)
+	}
+	if state.Prompts == nil {
+		state.Prompts = map[string]int{}
+	}
+	return state
+}
+
+func (s defaultsState) save() error {
+	content, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(defaultsStateFile(), content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", defaultsStateFile(), err)
+	}
+	return nil
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// SyncDefaults merges the built-in prompts of a new app version on startup.
+// New built-in prompts show up through the built-in layer. Personal copies that equal
+// the current or an earlier built-in prompt are removed, so the new version is used.
+// Edited copies of changed built-in prompts are returned to let the user decide
+func SyncDefaults() ([]DefaultUpdate, error) {
+	manifest, err := LoadDefaultsManifest()
+	if err != nil {
+		return nil, err
+	}
+	state := loadDefaultsState()
+	if state.Version >= manifest.Version {
+		return nil, nil
+	}
+	fmt.Printf("Merging built-in prompts version %d, last seen %d\n", manifest.Version, state.Version)
+
+	actions, err := promptActions(builtInPrompts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list built-in prompts: %v", err)
+	}
+	var updates []DefaultUpdate
+	for _, action := range actions {
+		version := manifest.promptVersion(action)
+		seen, known := state.Prompts[action]
+		if !known && state.Version > 0 {
+			fmt.Printf("New built-in action: %s\n", action)
+		}
+
+		personal, err := os.ReadFile(PromptFile(action))
+		if err != nil {
+			state.Prompts[action] = version
+			continue
+		}
+		builtIn, err := BuiltInPrompt(action)
+		if err != nil {
+			return nil, err
+		}
+		hash := contentHash(string(personal))
+		switch {
+		case string(personal) == builtIn || slices.Contains(manifest.Prompts[action].Previous, hash):
+			// An unchanged copy of the defaults, e.g. from the first start of an older version
+			if err := removePersonalPrompt(action, string(personal)); err != nil {
+				return nil, err
+			}
+			fmt.Printf("Action %s uses the built-in prompt version %d\n", action, version)
+			state.Prompts[action] = version
+		case version <= max(seen, 1):
+			// Already offered and kept, or a copy edited before the built-in prompt changed.
+			// Without a record, e.g. on the first start after an upgrade, the copy is of version 1
+			state.Prompts[action] = max(seen, version)
+		default:
+			updates = append(updates, DefaultUpdate{
+				Action:   action,
+				Version:  version,
+				Personal: string(personal),
+				Default:  builtIn,
+			})
+		}
+	}
+
+	// Offered updates are recorded when the user decides, until then they are offered on every start
+	if len(updates) == 0 {
+		state.Version = manifest.Version
+	}
+	return updates, state.save()
+}
+
+// Apply replaces the personal copy with the new built-in prompt, the copy stays in the history
+func (u DefaultUpdate) Apply() error {
+	if err := removePersonalPrompt(u.Action, u.Personal); err != nil {
+		return err
+	}
+	fmt.Printf("Action %s uses the built-in prompt version %d\n", u.Action, u.Version)
+	return u.seen()
+}
+
+// Keep keeps the personal copy, the user is asked again for the next version of the built-in prompt
+func (u DefaultUpdate) Keep() error {
+	fmt.Printf("Keeping personal prompt of %s instead of built-in version %d\n", u.Action, u.Version)
+	return u.seen()
+}
+
+func (u DefaultUpdate) seen() error {
+	state := loadDefaultsState()
+	state.Prompts[u.Action] = u.Version
+	return state.save()
+}
+
+// RestoreDefaultPrompt removes the personal copy of an action, so its built-in prompt is used again.
+// A team prompt of the action overrides the built-in prompt, without a personal copy there is
+// nothing to restore. The copy stays in the history
+func RestoreDefaultPrompt(actionType string) error {
+	if _, err := BuiltInPrompt(actionType); err != nil {
+		return err
+	}
+	personal, err := os.ReadFile(PromptFile(actionType))
+	if errors.Is(err, fs.ErrNotExist) {
+		if _, source, err := SharedPrompt(actionType); err == nil && source == SourceTeam {
+			return fmt.Errorf("%s uses the team prompt, which overrides the built-in prompt and cannot be restored", actionType)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read prompt file of %s: %v", actionType, err)
+	}
+	if err := removePersonalPrompt(actionType, string(personal)); err != nil {
+		return err
+	}
+	fmt.Printf("Restored built-in prompt of %s\n", actionType)
+	return nil
+}
@@ -0,0 +1,187 @@
+package configuration
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSyncDefaults(t *testing.T) {
+	manifest := "version: 2\nprompts:\n  a:\n    version: 2\n    previous:\n      - " + contentHash("A1") + "\n"
+	tests := []struct {
+		name     string
+		state    string
+		personal map[string]string
+		// updates are the offered actions, kept the personal copies left after the sync
+		updates []string
+		kept    []string
+		seen    map[string]int
+		version int
+	}{
+		{
+			name:     "unchanged copy removed",
+			personal: map[string]string{"a": "A2"},
+			seen:     map[string]int{"a": 2, "b": 1},
+			version:  2,
+		},
+		{
+			name:     "copy of an earlier version removed",
+			personal: map[string]string{"a": "A1"},
+			seen:     map[string]int{"a": 2, "b": 1},
+			version:  2,
+		},
+		{
+			name:     "edited copy of a changed prompt offered",
+			personal: map[string]string{"a": "mine"},
+			updates:  []string{"a"},
+			kept:     []string{"a"},
+			seen:     map[string]int{"b": 1},
+			version:  0,
+		},
+		{
+			name:     "edited copy without recorded version kept",
+			personal: map[string]string{"b": "mine"},
+			kept:     []string{"b"},
+			seen:     map[string]int{"a": 2, "b": 1},
+			version:  2,
+		},
+		{
+			name:     "already offered",
+			state:    "version: 1\nprompts:\n  a: 2\n",
+			personal: map[string]string{"a": "mine"},
+			kept:     []string{"a"},
+			seen:     map[string]int{"a": 2, "b": 1},
+			version:  2,
+		},
+		{
+			name:     "offered again for a newer version",
+			state:    "version: 1\nprompts:\n  a: 1\n",
+			personal: map[string]string{"a": "mine"},
+			updates:  []string{"a"},
+			kept:     []string{"a"},
+			seen:     map[string]int{"a": 1, "b": 1},
+			version:  1,
+		},
+		{
+			name:     "defaults already merged",
+			state:    "version: 2\nprompts: {}\n",
+			personal: map[string]string{"a": "A2"},
+			kept:     []string{"a"},
+			seen:     map[string]int{},
+			version:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupPromptStore(t, fstest.MapFS{
+				"manifest.yaml": {Data: []byte(manifest)},
+				"prompt-a.txt":  {Data: []byte("A2")},
+				"prompt-b.txt":  {Data: []byte("B")},
+			})
+			if tt.state != "" {
+				if err := os.WriteFile(defaultsStateFile(), []byte(tt.state), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for action, content := range tt.personal {
+				if err := os.WriteFile(PromptFile(action), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			updates, err := SyncDefaults()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var offered []string
+			for _, update := range updates {
+				offered = append(offered, update.Action)
+			}
+			if !maps.Equal(setOf(offered), setOf(tt.updates)) {
+				t.Errorf("updates = %v, want %v", offered, tt.updates)
+			}
+			var kept []string
+			for action := range tt.personal {
+				if _, err := os.Stat(PromptFile(action)); err == nil {
+					kept = append(kept, action)
+				}
+			}
+			if !maps.Equal(setOf(kept), setOf(tt.kept)) {
+				t.Errorf("personal copies = %v, want %v", kept, tt.kept)
+			}
+			state := loadDefaultsState()
+			if !maps.Equal(state.Prompts, tt.seen) || state.Version != tt.version {
+				t.Errorf("state = %d %v, want %d %v", state.Version, state.Prompts, tt.version, tt.seen)
+			}
+		})
+	}
+}
+
+// setupPromptStore uses an empty personal directory and the given built-in prompts for a test
+func setupPromptStore(t *testing.T, builtIn fstest.MapFS) {
+	configPath, prompts, team := ConfigPath, builtInPrompts, teamPromptDir
+	t.Cleanup(func() {
+		ConfigPath, builtInPrompts, teamPromptDir = configPath, prompts, team
+		InvalidatePrompts()
+	})
+	ConfigPath = t.TempDir()
+	builtInPrompts = builtIn
+	teamPromptDir = ""
+	InvalidatePrompts()
+}
+
+func setOf(values []string) map[string]bool {
+	set := map[string]bool{}
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+func TestRestoreDefaultPrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		team     bool
+		personal bool
+		err      string
+	}{
+		{name: "personal copy removed", personal: true},
+		{name: "built-in prompt in use", personal: false},
+		{name: "personal copy of a team prompt removed", team: true, personal: true},
+		{name: "team prompt in use", team: true, err: "team prompt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupPromptStore(t, fstest.MapFS{"prompt-a.txt": {Data: []byte("A")}})
+			if tt.team {
+				teamPromptDir = t.TempDir()
+				if err := os.WriteFile(filepath.Join(teamPromptDir, "prompt-a.txt"), []byte("team"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.personal {
+				if err := os.WriteFile(PromptFile("a"), []byte("mine"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			InvalidatePrompts()
+
+			err := RestoreDefaultPrompt("a")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := os.Stat(PromptFile("a")); err == nil {
+				t.Errorf("personal copy not removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxPromptVersions is the number of versions kept per action, older ones are removed
+const MaxPromptVersions = 100
+
+// versionTimeFormat names the snapshot files, it sorts by time
+const versionTimeFormat = "20060102-150405.000"
+
+// PromptVersion is a saved version of a prompt file
+type PromptVersion struct {
+	Action string
+	Time   time.Time
+	File   string
+}
+
+// Label describes the version for a list, e.g. "2025-01-31 09:30:12"
+func (v PromptVersion) Label() string {
+	return v.Time.Format("2006-01-02 15:04:05")
+}
+
+// Content reads the prompt file content of the version
+func (v PromptVersion) Content() (string, error) {
+	content, err := os.ReadFile(v.File)
+	if err != nil {
+		return "", fmt.Errorf("failed to read version %s of %s: %v", v.Label(), v.Action, err)
+	}
+	return string(content), nil
+}
+
+// HistoryDir returns the directory with the versions of an action's prompt
+func HistoryDir(actionType string) string {
+	return filepath.Join(ConfigPath, "history", actionType)
+}
+
+// ListPromptVersions returns the saved versions of an action's prompt, newest first
+func ListPromptVersions(actionType string) ([]PromptVersion, error) {
+	entries, err := os.ReadDir(HistoryDir(actionType))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %v", actionType, err)
+	}
+	var versions []PromptVersion
+	for _, entry := range entries {
+		stamp, ok := strings.CutSuffix(entry.Name(), ".txt")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		t, err := time.ParseInLocation(versionTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, PromptVersion{
+			Action: actionType,
+			Time:   t,
+			File:   filepath.Join(HistoryDir(actionType), entry.Name()),
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Time.After(versions[j].Time)
+	})
+	return versions, nil
+}
+
+// snapshotPrompt stores content as newest version of an action's prompt
+// An unchanged prompt adds no version, a file saved before the history existed
+// is kept as first version
+func snapshotPrompt(actionType, content string) error {
+	versions, err := ListPromptVersions(actionType)
+	if err != nil {
+		return err
+	}
+	if len(versions) > 0 {
+		if latest, err := versions[0].Content(); err == nil && latest == content {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(HistoryDir(actionType), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %v", err)
+	}
+
+	if len(versions) == 0 {
+		file := PromptFile(actionType)
+		if previous, err := os.ReadFile(file); err == nil && string(previous) != content {
+			info, err := os.Stat(file)
+			if err == nil {
+				if err := writeVersion(actionType, info.ModTime(), string(previous)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := writeVersion(actionType, time.Now(), content); err != nil {
+		return err
+	}
+	return prunePromptVersions(actionType)
+}
+
+// writeVersion writes a version file of an action's prompt
+func writeVersion(actionType string, t time.Time, content string) error {
+	file := filepath.Join(HistoryDir(actionType), t.Format(versionTimeFormat)+".txt")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write version of %s: %v", actionType, err)
+	}
+	return nil
+}
+
+// prunePromptVersions removes the oldest versions above MaxPromptVersions
+func prunePromptVersions(actionType string) error {
+	versions, err := ListPromptVersions(actionType)
+	if err != nil {
+		return err
+	}
+	for i := MaxPromptVersions; i < len(versions); i++ {
+		if err := os.Remove(versions[i].File); err != nil {
+			return fmt.Errorf("failed to remove old version of %s: %v", actionType, err)
+		}
+	}
+	return nil
+}
+
+// RestorePromptVersion saves the content of a version as the prompt of its action
+// The restore is itself a new version, so it can be undone
+func RestorePromptVersion(version PromptVersion) error {
+	content, err := version.Content()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restoring prompt %s to version %s\n", version.Action, version.Label())
+	return SavePromptContent(version.Action, content)
+}
+
+// DiffOp marks a line of a diff
+type DiffOp byte
+
+const (
+	DiffEqual  DiffOp = ' '
+	DiffDelete DiffOp = '-'
+	DiffInsert DiffOp = '+'
+)
+
+// DiffLine is a line of a diff
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// DiffLines returns the line diff from a to b based on their longest common subsequence
+func DiffLines(a, b string) []DiffLine {
+	from := strings.Split(a, "\n")
+	to := strings.Split(b, "\n")
+
+	// lcs[i][j] is the length of the common subsequence of from[i:] and to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			diff = append(diff, DiffLine{DiffEqual, from[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{DiffDelete, from[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{DiffInsert, to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		diff = append(diff, DiffLine{DiffDelete, from[i]})
+	}
+	for ; j < len(to); j++ {
+		diff = append(diff, DiffLine{DiffInsert, to[j]})
+	}
+	return diff
+}
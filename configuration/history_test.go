@@ -0,0 +1,37 @@
+package configuration
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []string
+	}{
+		{name: "equal", a: "a\nb", b: "a\nb", want: []string{" a", " b"}},
+		{name: "both empty", a: "", b: "", want: []string{" "}},
+		{name: "from empty", a: "", b: "a", want: []string{"-", "+a"}},
+		{name: "line inserted", a: "a\nc", b: "a\nb\nc", want: []string{" a", "+b", " c"}},
+		{name: "line deleted", a: "a\nb\nc", b: "a\nc", want: []string{" a", "-b", " c"}},
+		{name: "line changed", a: "a\nb\nc", b: "a\nx\nc", want: []string{" a", "-b", "+x", " c"}},
+		{name: "appended", a: "a", b: "a\nb\nc", want: []string{" a", "+b", "+c"}},
+		{name: "truncated", a: "a\nb\nc", b: "a", want: []string{" a", "-b", "-c"}},
+		{name: "moved line", a: "a\nb\nc", b: "b\nc\na", want: []string{"-a", " b", " c", "+a"}},
+		{name: "nothing in common", a: "a\nb", b: "c", want: []string{"-a", "-b", "+c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, line := range DiffLines(tt.a, tt.b) {
+				got = append(got, string(line.Op)+line.Text)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DiffLines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package configuration
+
+import (
+	"fmt"
+
+	"github.com/megaproaktiv/audionote-config/llm"
+)
+
+// Inference holds inference parameters, nil and empty values are not set
+// Global values apply to all actions, prompt front matter and action values override them
+type Inference struct {
+	Temperature   *float64 `mapstructure:"temperature" yaml:"temperature"`
+	TopP          *float64 `mapstructure:"top_p" yaml:"top_p"`
+	MaxTokens     *int     `mapstructure:"max_tokens" yaml:"max_tokens"`
+	StopSequences []string `mapstructure:"stop_sequences" yaml:"stop_sequences"`
+}
+
+// IsZero reports whether no parameter is set
+func (i Inference) IsZero() bool {
+	return i.Temperature == nil && i.TopP == nil && i.MaxTokens == nil && len(i.StopSequences) == 0
+}
+
+// Validate checks the ranges the Converse API accepts
+func (i Inference) Validate() error {
+	if i.Temperature != nil && (*i.Temperature < 0 || *i.Temperature > 1) {
+		return fmt.Errorf("temperature %g is not between 0 and 1", *i.Temperature)
+	}
+	if i.TopP != nil && (*i.TopP < 0 || *i.TopP > 1) {
+		return fmt.Errorf("top-p %g is not between 0 and 1", *i.TopP)
+	}
+	if i.MaxTokens != nil && *i.MaxTokens < 1 {
+		return fmt.Errorf("max tokens %d must be at least 1", *i.MaxTokens)
+	}
+	if len(i.StopSequences) > llm.MaxStopSequences {
+		return fmt.Errorf("at most %d stop sequences are allowed", llm.MaxStopSequences)
+	}
+	return nil
+}
+
+// Merge returns the parameters with the set values of override replacing these
+func (i Inference) Merge(override Inference) Inference {
+	if override.Temperature != nil {
+		i.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		i.TopP = override.TopP
+	}
+	if override.MaxTokens != nil {
+		i.MaxTokens = override.MaxTokens
+	}
+	if len(override.StopSequences) > 0 {
+		i.StopSequences = override.StopSequences
+	}
+	return i
+}
+
+// Params converts the parameters for a model request
+func (i Inference) Params() llm.Params {
+	var params llm.Params
+	if i.Temperature != nil {
+		temperature := float32(*i.Temperature)
+		params.Temperature = &temperature
+	}
+	if i.TopP != nil {
+		topP := float32(*i.TopP)
+		params.TopP = &topP
+	}
+	if i.MaxTokens != nil {
+		maxTokens := int32(*i.MaxTokens)
+		params.MaxTokens = &maxTokens
+	}
+	params.StopSequences = i.StopSequences
+	return params
+}
+
+// settings returns the set parameters for the config file
+func (i Inference) settings() map[string]any {
+	settings := map[string]any{}
+	if i.Temperature != nil {
+		settings["temperature"] = *i.Temperature
+	}
+	if i.TopP != nil {
+		settings["top_p"] = *i.TopP
+	}
+	if i.MaxTokens != nil {
+		settings["max_tokens"] = *i.MaxTokens
+	}
+	if len(i.StopSequences) > 0 {
+		settings["stop_sequences"] = i.StopSequences
+	}
+	return settings
+}
+
+// SetActionInference stores the overrides of an action, zero parameters remove them
+func (c *Config) SetActionInference(action string, inference Inference) {
+	if inference.IsZero() {
+		delete(c.ActionInference, action)
+		return
+	}
+	if c.ActionInference == nil {
+		c.ActionInference = map[string]Inference{}
+	}
+	c.ActionInference[action] = inference
+}
@@ -0,0 +1,551 @@
+package configuration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterDelimiter starts and ends the YAML front matter at the top of a prompt file
+const frontMatterDelimiter = "---"
+
+// PromptSettings are declared in the optional YAML front matter of a prompt file
+//
+//	---
+//	model: eu.anthropic.claude-3-7-sonnet-20250219-v1:0
+//	system: You are a technical writer.
+//	inference:
+//	  max_tokens: 8000
+//	output: "{name}-paper.md"
+//	language: de-DE
+//	---
+type PromptSettings struct {
+	// Model replaces the model of the settings
+	Model string `yaml:"model"`
+	// System is sent as system prompt
+	System string `yaml:"system"`
+	// Inference overrides the global inference parameters
+	Inference Inference `yaml:"inference"`
+	// Output is the result file, relative paths are placed next to the output file of the settings.
+	// {name} is replaced by the audio file name without extension, {action} by the action
+	// and {date} by the date of the run
+	Output string `yaml:"output"`
+	// Language is the transcript language selected with the action
+	Language string `yaml:"language"`
+	// Inputs are asked for when the action is started, e.g. {{.Inputs.audience}}
+	Inputs []PromptInput `yaml:"inputs"`
+}
+
+// Types of prompt inputs
+const (
+	InputText      = "text"
+	InputMultiline = "multiline"
+	InputChoice    = "choice"
+	InputNumber    = "number"
+	InputBool      = "bool"
+)
+
+// PromptInput is a value the user enters when an action is started
+//
+//	inputs:
+//	  - name: audience
+//	    label: Target audience
+//	    default: developers
+//	  - name: tone
+//	    type: choice
+//	    choices: [formal, casual]
+type PromptInput struct {
+	// Name is the key in the template, letters, digits and underscores
+	Name  string `yaml:"name"`
+	Label string `yaml:"label"`
+	// Type is text, multiline, choice, number or bool, empty is text
+	Type    string   `yaml:"type"`
+	Default string   `yaml:"default"`
+	Choices []string `yaml:"choices"`
+}
+
+// Title returns the label of the input or its name
+func (i PromptInput) Title() string {
+	if i.Label != "" {
+		return i.Label
+	}
+	return i.Name
+}
+
+// validate checks name, type and choices of an input
+func (i PromptInput) validate() error {
+	if !inputNamePattern.MatchString(i.Name) {
+		return fmt.Errorf("input name %q must start with a letter and contain only letters, digits and _", i.Name)
+	}
+	switch i.Type {
+	case "", InputText, InputMultiline:
+	case InputChoice:
+		if len(i.Choices) == 0 {
+			return fmt.Errorf("input %s of type choice has no choices", i.Name)
+		}
+		if i.Default != "" && !slices.Contains(i.Choices, i.Default) {
+			return fmt.Errorf("default %q of input %s is not one of its choices", i.Default, i.Name)
+		}
+	case InputNumber:
+		if _, err := i.Value(i.Default); err != nil && i.Default != "" {
+			return err
+		}
+	case InputBool:
+		if _, err := i.Value(i.Default); err != nil && i.Default != "" {
+			return err
+		}
+	default:
+		return fmt.Errorf("input %s has unknown type %q, use text, multiline, choice, number or bool", i.Name, i.Type)
+	}
+	return nil
+}
+
+// Value converts an entered value for the template: numbers to float64 and bools to bool
+func (i PromptInput) Value(text string) (any, error) {
+	switch i.Type {
+	case InputNumber:
+		if text == "" {
+			return 0.0, nil
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("input %s: %q is not a number", i.Name, text)
+		}
+		return value, nil
+	case InputBool:
+		if text == "" {
+			return false, nil
+		}
+		value, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("input %s: %q is not true or false", i.Name, text)
+		}
+		return value, nil
+	default:
+		return text, nil
+	}
+}
+
+// inputNamePattern matches the names of prompt inputs usable as map key in templates
+var inputNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// Prompt is a prompt file split into its front matter settings and the prompt text
+type Prompt struct {
+	Action   string
+	Settings PromptSettings
+	Body     string
+	// line is the number of front matter lines before the body
+	line int
+}
+
+// PromptData are the variables of a prompt template, e.g. {{.FileName}}
+type PromptData struct {
+	Transcript      string
+	FileName        string
+	RecordedAt      time.Time
+	Language        string
+	DurationMinutes int
+	Speakers        int
+	// Inputs are the values of the inputs of the front matter by name
+	Inputs map[string]any
+}
+
+// samplePromptData is used to check templates in the editor
+var samplePromptData = PromptData{
+	Transcript:      "Sample transcript.",
+	FileName:        "sample.m4a",
+	RecordedAt:      time.Date(2025, 1, 31, 9, 30, 0, 0, time.Local),
+	Language:        "en-US",
+	DurationMinutes: 42,
+	Speakers:        2,
+}
+
+// ParsePrompt splits the content of a prompt file into the front matter settings and the
+// prompt text. Content without front matter is returned unchanged with empty settings
+func ParsePrompt(content string) (PromptSettings, string, error) {
+	var settings PromptSettings
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, frontMatterDelimiter+"\n") {
+		return settings, content, nil
+	}
+
+	rest := strings.TrimPrefix(normalized, frontMatterDelimiter+"\n")
+	var frontMatter, body string
+	if strings.HasPrefix(rest, frontMatterDelimiter+"\n") || rest == frontMatterDelimiter {
+		// Empty front matter
+		body = strings.TrimPrefix(strings.TrimPrefix(rest, frontMatterDelimiter), "\n")
+	} else {
+		end := strings.Index(rest, "\n"+frontMatterDelimiter+"\n")
+		if end < 0 {
+			if !strings.HasSuffix(rest, "\n"+frontMatterDelimiter) {
+				return settings, content, errors.New("front matter is not closed with ---")
+			}
+			end = len(rest) - len(frontMatterDelimiter) - 1
+		}
+		frontMatter = rest[:end]
+		body = strings.TrimPrefix(rest[end+1+len(frontMatterDelimiter):], "\n")
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewBufferString(frontMatter))
+	// Misspelled keys are reported instead of silently ignored
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&settings); err != nil && !errors.Is(err, io.EOF) {
+		return settings, content, fmt.Errorf("invalid front matter: %v", err)
+	}
+	if err := settings.Inference.Validate(); err != nil {
+		return settings, content, fmt.Errorf("invalid front matter: %v", err)
+	}
+	names := map[string]bool{}
+	for _, input := range settings.Inputs {
+		if err := input.validate(); err != nil {
+			return settings, content, fmt.Errorf("invalid front matter: %v", err)
+		}
+		// Remembered values are stored by lower case name
+		if names[strings.ToLower(input.Name)] {
+			return settings, content, fmt.Errorf("invalid front matter: input %s is declared twice", input.Name)
+		}
+		names[strings.ToLower(input.Name)] = true
+	}
+	return settings, body, nil
+}
+
+// NewPrompt parses the content of the prompt file of an action
+func NewPrompt(actionType, content string) (*Prompt, error) {
+	settings, body, err := ParsePrompt(content)
+	if err != nil {
+		return nil, fmt.Errorf("prompt file of %s: %v", actionType, err)
+	}
+	return &Prompt{
+		Action:   actionType,
+		Settings: settings,
+		Body:     body,
+		line:     strings.Count(content, "\n") - strings.Count(body, "\n"),
+	}, nil
+}
+
+// LoadPrompt reads the prompt file of an action and parses its front matter
+func LoadPrompt(actionType string) (*Prompt, error) {
+	content, err := LoadPromptContent(actionType)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrompt(actionType, content)
+}
+
+// PartialsDir returns the directory of the personal prompt partials, <name>.txt is included
+// in prompts with {{template "<name>" .}}. Built-in partials are embedded from config-default/partials
+func PartialsDir() string {
+	return filepath.Join(ConfigPath, "partials")
+}
+
+// ContextFile returns the global context file, which is sent as system prompt for every action
+func ContextFile() string {
+	return filepath.Join(ConfigPath, "context.txt")
+}
+
+// LoadGlobalContext reads the global context, empty if there is no context file
+func LoadGlobalContext() (string, error) {
+	content, err := os.ReadFile(ContextFile())
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read global context: %v", err)
+	}
+	return string(content), nil
+}
+
+// SaveGlobalContext writes the global context file
+func SaveGlobalContext(content string) error {
+	if err := os.MkdirAll(ConfigPath, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(ContextFile(), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write global context: %v", err)
+	}
+	return nil
+}
+
+// partialLayers returns the built-in partials and the personal partials, which override them
+func partialLayers() []fs.FS {
+	var layers []fs.FS
+	if builtInPrompts != nil {
+		if sub, err := fs.Sub(builtInPrompts, "partials"); err == nil {
+			layers = append(layers, sub)
+		}
+	}
+	return append(layers, os.DirFS(PartialsDir()))
+}
+
+// ListPartials returns the names of the built-in and personal prompt partials
+func ListPartials() ([]string, error) {
+	var names []string
+	for _, layer := range partialLayers() {
+		entries, err := fs.ReadDir(layer, ".")
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := strings.TrimSuffix(entry.Name(), ".txt")
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".txt") && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// loadPartial reads a partial, a personal partial overrides the built-in one of the same name
+func loadPartial(name string) (string, error) {
+	layers := partialLayers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		content, err := fs.ReadFile(layers[i], name+".txt")
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	}
+	return "", fs.ErrNotExist
+}
+
+// template parses the prompt text as Go template together with the partials
+// The body is shifted by the front matter lines, so errors report the line of the file
+func (p *Prompt) template() (*template.Template, error) {
+	// Misspelled input names fail instead of rendering "<no value>"
+	tmpl, err := template.New(fmt.Sprintf("prompt-%s.txt", p.Action)).Option("missingkey=error").Parse(strings.Repeat("\n", p.line) + p.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+
+	partials, err := ListPartials()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read partials: %v", err)
+	}
+	for _, name := range partials {
+		// A template defined in the prompt replaces the partial
+		if tmpl.Lookup(name) != nil {
+			continue
+		}
+		content, err := loadPartial(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read partial %s: %v", name, err)
+		}
+		if _, err := tmpl.New(name).Parse(content); err != nil {
+			return nil, fmt.Errorf("invalid partial %s: %v", name, err)
+		}
+	}
+	return tmpl, nil
+}
+
+// System returns the system prompt of a run: the global context followed by the system
+// prompt of the front matter
+func (p *Prompt) System() (string, error) {
+	globalContext, err := LoadGlobalContext()
+	if err != nil {
+		return "", err
+	}
+	var parts []string
+	for _, part := range []string{globalContext, p.Settings.System} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// Render executes the prompt text as template with the data of a run
+// The transcript is appended when the template does not reference {{.Transcript}}
+func (p *Prompt) Render(data PromptData) (string, error) {
+	tmpl, err := p.template()
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("invalid template: %v", err)
+	}
+	rendered := strings.TrimPrefix(out.String(), strings.Repeat("\n", p.line))
+	if !referencesField(tmpl, tmpl.Root, "Transcript", map[string]bool{}) {
+		rendered += "\n" + data.Transcript
+	}
+	return rendered, nil
+}
+
+// CheckPrompt parses the content of a prompt file and renders it with sample data
+// It returns whether the transcript is placed by the template or appended
+func CheckPrompt(actionType, content string) (placed bool, err error) {
+	prompt, err := NewPrompt(actionType, content)
+	if err != nil {
+		return false, err
+	}
+	tmpl, err := prompt.template()
+	if err != nil {
+		return false, err
+	}
+	data := samplePromptData
+	if data.Inputs, err = prompt.InputValues(nil); err != nil {
+		return false, err
+	}
+	if _, err := prompt.Render(data); err != nil {
+		return false, err
+	}
+	return referencesField(tmpl, tmpl.Root, "Transcript", map[string]bool{}), nil
+}
+
+// UsesSpeakers reports whether the prompt references {{.Speakers}},
+// speaker labels of the transcription are only needed then
+func (p *Prompt) UsesSpeakers() bool {
+	tmpl, err := p.template()
+	if err != nil {
+		return false
+	}
+	return referencesField(tmpl, tmpl.Root, "Speakers", map[string]bool{})
+}
+
+// InputValues returns the template values of the inputs. Entered values are looked up
+// by lower case input name, inputs without entered value get their default
+func (p *Prompt) InputValues(entered map[string]string) (map[string]any, error) {
+	values := map[string]any{}
+	for _, input := range p.Settings.Inputs {
+		text, ok := entered[strings.ToLower(input.Name)]
+		if !ok {
+			text = input.Default
+		}
+		value, err := input.Value(text)
+		if err != nil {
+			return nil, err
+		}
+		values[input.Name] = value
+	}
+	return values, nil
+}
+
+// referencesField reports whether a template references a field of the data, e.g. {{.Transcript}}
+// Included templates are followed, visited prevents endless recursion
+func referencesField(tmpl *template.Template, node parse.Node, field string, visited map[string]bool) bool {
+	references := func(node parse.Node) bool {
+		return referencesField(tmpl, node, field, visited)
+	}
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if references(child) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return references(n.Pipe)
+	case *parse.IfNode:
+		return references(n.Pipe) || references(n.List) || references(n.ElseList)
+	case *parse.RangeNode:
+		return references(n.Pipe) || references(n.List) || references(n.ElseList)
+	case *parse.WithNode:
+		return references(n.Pipe) || references(n.List) || references(n.ElseList)
+	case *parse.TemplateNode:
+		if references(n.Pipe) {
+			return true
+		}
+		if included := tmpl.Lookup(n.Name); included != nil && included.Tree != nil && !visited[n.Name] {
+			visited[n.Name] = true
+			return references(included.Root)
+		}
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, command := range n.Cmds {
+			if references(command) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if references(arg) {
+				return true
+			}
+		}
+	case *parse.FieldNode:
+		return len(n.Ident) > 0 && n.Ident[0] == field
+	case *parse.VariableNode:
+		// {{$.Transcript}}
+		return len(n.Ident) > 1 && n.Ident[1] == field
+	case *parse.ChainNode:
+		return references(n.Node)
+	}
+	return false
+}
+
+// PromptModel returns the model of the front matter or the model of the settings
+func (c *Config) PromptModel(prompt *Prompt) string {
+	if prompt.Settings.Model != "" {
+		return prompt.Settings.Model
+	}
+	return c.Model
+}
+
+// PromptInference returns the inference parameters of a run: the global parameters,
+// overridden by the front matter, overridden by the action parameters of the settings
+func (c *Config) PromptInference(prompt *Prompt) Inference {
+	return c.Inference.Merge(prompt.Settings.Inference).Merge(c.ActionInference[prompt.Action])
+}
+
+// PromptOutputPath returns the result file of a run for an audio file
+// The output of the front matter is relative to the output directory and must stay inside it,
+// prompts, e.g. synced team prompts, cannot write elsewhere
+func (c *Config) PromptOutputPath(prompt *Prompt, audioFile string, now time.Time) (string, error) {
+	pattern := prompt.Settings.Output
+	if pattern == "" {
+		return c.OutputPath, nil
+	}
+	name := strings.TrimSuffix(filepath.Base(audioFile), filepath.Ext(audioFile))
+	output := strings.NewReplacer(
+		"{name}", name,
+		"{action}", prompt.Action,
+		"{date}", now.Format("2006-01-02"),
+	).Replace(pattern)
+	if filepath.IsAbs(output) || strings.HasPrefix(output, "~") || strings.HasPrefix(output, "/") {
+		return "", fmt.Errorf("output %q of prompt %s must be relative to the output directory", pattern, prompt.Action)
+	}
+	outputDir := filepath.Clean(filepath.Dir(c.OutputPath))
+	output = filepath.Join(outputDir, output)
+	if rel, err := filepath.Rel(outputDir, output); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("output %q of prompt %s is outside the output directory %s", pattern, prompt.Action, outputDir)
+	}
+	return output, nil
+}
+
+// SetPromptInputs remembers the values entered for the inputs of an action
+func (c *Config) SetPromptInputs(action string, values map[string]string) {
+	if c.PromptInputs == nil {
+		c.PromptInputs = map[string]map[string]string{}
+	}
+	remembered := map[string]string{}
+	for name, value := range values {
+		remembered[strings.ToLower(name)] = value
+	}
+	c.PromptInputs[action] = remembered
+}
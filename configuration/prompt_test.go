@@ -0,0 +1,176 @@
+package configuration
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestParsePrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		settings PromptSettings
+		body     string
+		err      string
+	}{
+		{
+			name:    "no front matter",
+			content: "Summarize:\n{{.Transcript}}\n",
+			body:    "Summarize:\n{{.Transcript}}\n",
+		},
+		{
+			name:    "delimiter not on the first line",
+			content: "Summarize\n---\nmodel: x\n---\n",
+			body:    "Summarize\n---\nmodel: x\n---\n",
+		},
+		{
+			name:    "empty front matter",
+			content: "---\n---\nSummarize\n",
+			body:    "Summarize\n",
+		},
+		{
+			name:    "empty front matter without body",
+			content: "---\n---",
+			body:    "",
+		},
+		{
+			name:     "settings",
+			content:  "---\nmodel: m\nsystem: s\noutput: \"{name}.md\"\nlanguage: de-DE\n---\nSummarize\n",
+			settings: PromptSettings{Model: "m", System: "s", Output: "{name}.md", Language: "de-DE"},
+			body:     "Summarize\n",
+		},
+		{
+			name:     "closed at the end of the file",
+			content:  "---\nmodel: m\n---",
+			settings: PromptSettings{Model: "m"},
+			body:     "",
+		},
+		{
+			name:     "CRLF line endings",
+			content:  "---\r\nmodel: m\r\n---\r\nSummarize\r\n",
+			settings: PromptSettings{Model: "m"},
+			body:     "Summarize\n",
+		},
+		{
+			name:    "unclosed front matter",
+			content: "---\nmodel: m\nSummarize\n",
+			err:     "not closed",
+		},
+		{
+			name:    "unknown key",
+			content: "---\nmodle: m\n---\nSummarize\n",
+			err:     "field modle not found",
+		},
+		{
+			name:    "invalid inference",
+			content: "---\ninference:\n  temperature: 3\n---\nSummarize\n",
+			err:     "invalid front matter",
+		},
+		{
+			name:    "invalid yaml",
+			content: "---\nmodel: [m\n---\nSummarize\n",
+			err:     "invalid front matter",
+		},
+		{
+			name:    "input declared twice",
+			content: "---\ninputs:\n  - name: tone\n  - name: Tone\n---\nSummarize\n",
+			err:     "declared twice",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, body, err := ParsePrompt(tt.content)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if settings.Model != tt.settings.Model || settings.System != tt.settings.System ||
+				settings.Output != tt.settings.Output || settings.Language != tt.settings.Language {
+				t.Errorf("settings = %+v, want %+v", settings, tt.settings)
+			}
+			if body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestReferencesField(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     bool
+	}{
+		{"field", "Summarize {{.Transcript}}", true},
+		{"root variable", "Summarize {{$.Transcript}}", true},
+		{"variable of the data", "{{$data := .}}Summarize {{$data.Transcript}}", true},
+		{"other field", "Summarize {{.FileName}}", false},
+		{"other root variable", "Summarize {{$.FileName}}", false},
+		{"plain text", "Summarize the transcript", false},
+		{"if", "{{if .Transcript}}Summarize{{end}}", true},
+		{"else branch", "{{if .Speakers}}x{{else}}{{.Transcript}}{{end}}", true},
+		{"range", "{{range $.Inputs}}x{{else}}{{.Transcript}}{{end}}", true},
+		{"with", "{{with .FileName}}{{$.Transcript}}{{end}}", true},
+		{"pipeline", "{{.Transcript | printf \"%s\"}}", true},
+		{"function argument", "{{printf \"%s\" .Transcript}}", true},
+		{"included template", "{{define \"t\"}}{{.Transcript}}{{end}}Summarize {{template \"t\" .}}", true},
+		{"recursive template", "{{define \"t\"}}{{if .Speakers}}{{template \"t\" .}}{{end}}{{end}}{{template \"t\" .}}", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New("prompt").Parse(tt.template)
+			if err != nil {
+				t.Fatalf("invalid template: %v", err)
+			}
+			if got := referencesField(tmpl, tmpl.Root, "Transcript", map[string]bool{}); got != tt.want {
+				t.Errorf("referencesField = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptOutputPath(t *testing.T) {
+	config := &Config{OutputPath: filepath.Join("/home", "user", "notes", "result.md")}
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		output string
+		want   string
+		err    string
+	}{
+		{name: "no output", output: "", want: filepath.Join("/home", "user", "notes", "result.md")},
+		{name: "placeholders", output: "{name}-{action}-{date}.md", want: filepath.Join("/home", "user", "notes", "talk-paper-2026-10-18.md")},
+		{name: "subdirectory", output: "papers/{name}.md", want: filepath.Join("/home", "user", "notes", "papers", "talk.md")},
+		{name: "inner parent directory", output: "papers/../{name}.md", want: filepath.Join("/home", "user", "notes", "talk.md")},
+		{name: "absolute", output: "/etc/{name}.md", err: "must be relative"},
+		{name: "home directory", output: "~/.bashrc", err: "must be relative"},
+		{name: "parent directory", output: "../{name}.md", err: "outside the output directory"},
+		{name: "output directory itself", output: "papers/..", err: "outside the output directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt := &Prompt{Action: "paper", Settings: PromptSettings{Output: tt.output}}
+			got, err := config.PromptOutputPath(prompt, filepath.Join("/audio", "talk.m4a"), now)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("PromptOutputPath = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PromptSource is the layer a prompt comes from
+type PromptSource string
+
+// Prompt layers, a personal prompt overrides a team prompt, which overrides a built-in prompt
+const (
+	SourceBuiltIn  PromptSource = "built-in"
+	SourceTeam     PromptSource = "team"
+	SourcePersonal PromptSource = "personal"
+)
+
+// ReadOnly reports whether prompts of the source are never written by the app
+func (s PromptSource) ReadOnly() bool {
+	return s != SourcePersonal
+}
+
+// builtInPrompts holds the prompts embedded from config-default
+var builtInPrompts fs.FS
+
+// teamPromptDir is the directory with the team prompts, empty if there is none
+var teamPromptDir string
+
+// promptCache holds the listing of the prompt layers until a prompt file changes
+var promptCache struct {
+	sync.Mutex
+	valid   bool
+	entries []PromptEntry
+	err     error
+}
+
+// InvalidatePrompts drops the cached listing of the prompt layers, e.g. after syncing the team prompts
+func InvalidatePrompts() {
+	promptCache.Lock()
+	defer promptCache.Unlock()
+	promptCache.valid = false
+	promptCache.entries = nil
+	promptCache.err = nil
+}
+
+// PromptEntry is an action of the prompt store with the layer its prompt is read from
+type PromptEntry struct {
+	Action string
+	Source PromptSource
+	// File is the prompt file, empty for built-in prompts
+	File string
+	// Overrides lists the lower layers with a prompt of the same action
+	Overrides []PromptSource
+}
+
+// Describe tells where the prompt comes from, e.g. "personal, overrides built-in"
+func (e PromptEntry) Describe() string {
+	if len(e.Overrides) == 0 {
+		return string(e.Source)
+	}
+	var overrides []string
+	for _, source := range e.Overrides {
+		overrides = append(overrides, string(source))
+	}
+	return fmt.Sprintf("%s, overrides %s", e.Source, strings.Join(overrides, " and "))
+}
+
+// SetBuiltInPrompts sets the embedded defaults, the prompt files are in their config-default directory
+func SetBuiltInPrompts(defaultConfigFS fs.FS) {
+	sub, err := fs.Sub(defaultConfigFS, "config-default")
+	if err != nil {
+		fmt.Printf("Error reading built-in prompts: %v\n", err)
+		return
+	}
+	builtInPrompts = sub
+}
+
+// Team prompt sources
+const (
+	TeamSourceNone      = ""
+	TeamSourceDirectory = "directory"
+	TeamSourceS3        = "s3"
+)
+
+// TeamCacheDir holds the team prompts synced from S3
+func TeamCacheDir() string {
+	return filepath.Join(ConfigPath, "team-prompts")
+}
+
+// SetTeamPrompts sets the source of the team prompts: a directory, e.g. on a network share,
+// or a prefix in the S3 bucket, which is synced to TeamCacheDir. No source removes the team layer
+func (c *Config) SetTeamPrompts(source, dir, prefix string) {
+	dir = strings.TrimSpace(dir)
+	prefix = strings.TrimSpace(prefix)
+	c.TeamPromptSource = source
+	c.TeamPromptDir = dir
+	c.TeamPromptS3Prefix = prefix
+
+	switch source {
+	case TeamSourceDirectory:
+		if strings.HasPrefix(dir, "~/") {
+			if homeDir, err := os.UserHomeDir(); err == nil {
+				dir = filepath.Join(homeDir, dir[2:])
+			}
+		}
+		teamPromptDir = dir
+	case TeamSourceS3:
+		teamPromptDir = TeamCacheDir()
+	default:
+		teamPromptDir = ""
+	}
+	InvalidatePrompts()
+}
+
+// TeamPromptsFromS3 reports whether the team prompts are synced from the S3 bucket
+func (c *Config) TeamPromptsFromS3() bool {
+	return c.TeamPromptSource == TeamSourceS3 && c.S3Bucket != "" && c.TeamPromptS3Prefix != ""
+}
+
+// PromptFile returns the path of the personal prompt file of an action
+func PromptFile(actionType string) string {
+	return filepath.Join(ConfigPath, promptFileName(actionType))
+}
+
+func promptFileName(actionType string) string {
+	return fmt.Sprintf("prompt-%s.txt", actionType)
+}
+
+// promptActions returns the actions of the prompt-<action>.txt files in a file system
+func promptActions(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var actions []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "prompt-") || !strings.HasSuffix(name, ".txt") {
+			continue
+		}
+		// Extract action type from filename: prompt-ACTION.txt -> ACTION
+		actions = append(actions, strings.TrimSuffix(strings.TrimPrefix(name, "prompt-"), ".txt"))
+	}
+	return actions, nil
+}
+
+// promptLayer is a source of prompts, dir is empty for the embedded prompts
+type promptLayer struct {
+	source PromptSource
+	fsys   fs.FS
+	dir    string
+}
+
+// promptLayers returns the configured layers, lowest first
+func promptLayers() []promptLayer {
+	var layers []promptLayer
+	if builtInPrompts != nil {
+		layers = append(layers, promptLayer{source: SourceBuiltIn, fsys: builtInPrompts})
+	}
+	if teamPromptDir != "" {
+		layers = append(layers, promptLayer{source: SourceTeam, fsys: os.DirFS(teamPromptDir), dir: teamPromptDir})
+	}
+	return append(layers, promptLayer{source: SourcePersonal, fsys: os.DirFS(ConfigPath), dir: ConfigPath})
+}
+
+// ListPrompts returns the actions of all layers, each with the top layer that has its prompt
+// The listing is cached until InvalidatePrompts
+func ListPrompts() ([]PromptEntry, error) {
+	promptCache.Lock()
+	defer promptCache.Unlock()
+	if !promptCache.valid {
+		promptCache.entries, promptCache.err = scanPrompts()
+		promptCache.valid = true
+	}
+	entries := make([]PromptEntry, len(promptCache.entries))
+	for i, entry := range promptCache.entries {
+		entry.Overrides = slices.Clone(entry.Overrides)
+		entries[i] = entry
+	}
+	return entries, promptCache.err
+}
+
+// scanPrompts lists the prompt files of all layers
+func scanPrompts() ([]PromptEntry, error) {
+	entries := map[string]*PromptEntry{}
+	var errs []error
+	for _, layer := range promptLayers() {
+		actions, err := promptActions(layer.fsys)
+		if err != nil {
+			// A missing team directory, e.g. an unmounted share, leaves the other layers
+			errs = append(errs, fmt.Errorf("%s prompts: %v", layer.source, err))
+			continue
+		}
+		for _, action := range actions {
+			entry, ok := entries[action]
+			if !ok {
+				entry = &PromptEntry{Action: action}
+				entries[action] = entry
+			} else {
+				entry.Overrides = append([]PromptSource{entry.Source}, entry.Overrides...)
+			}
+			entry.Source = layer.source
+			entry.File = ""
+			if layer.dir != "" {
+				entry.File = filepath.Join(layer.dir, promptFileName(action))
+			}
+		}
+	}
+
+	var result []PromptEntry
+	for _, entry := range entries {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Action < result[j].Action })
+	return result, errors.Join(errs...)
+}
+
+// FindPrompt returns the prompt store entry of an action
+func FindPrompt(actionType string) (PromptEntry, error) {
+	entries, _ := ListPrompts()
+	i := slices.IndexFunc(entries, func(e PromptEntry) bool { return e.Action == actionType })
+	if i < 0 {
+		return PromptEntry{}, fmt.Errorf("no prompt for action %s", actionType)
+	}
+	return entries[i], nil
+}
+
+// LoadPromptFiles returns the actions of all prompt layers
+func LoadPromptFiles() ([]string, error) {
+	entries, err := ListPrompts()
+	var actionTypes []string
+	for _, entry := range entries {
+		actionTypes = append(actionTypes, entry.Action)
+	}
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	return actionTypes, nil
+}
+
+// LoadPromptContent reads the prompt of the given action type from its top layer
+func LoadPromptContent(actionType string) (string, error) {
+	content, _, err := readTopPrompt(actionType, promptLayers())
+	return content, err
+}
+
+// SharedPrompt reads the prompt an action uses without its personal copy, the team prompt
+// if there is one, otherwise the built-in prompt
+func SharedPrompt(actionType string) (string, PromptSource, error) {
+	layers := promptLayers()
+	return readTopPrompt(actionType, layers[:len(layers)-1])
+}
+
+// readTopPrompt reads the prompt of an action from the top layer that has it
+func readTopPrompt(actionType string, layers []promptLayer) (string, PromptSource, error) {
+	filename := promptFileName(actionType)
+	for i := len(layers) - 1; i >= 0; i-- {
+		content, err := fs.ReadFile(layers[i].fsys, filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read %s prompt file %s: %v", layers[i].source, filename, err)
+		}
+		fmt.Printf("Loading %s prompt file %s\n", layers[i].source, filename)
+		return string(content), layers[i].source, nil
+	}
+	return "", "", fmt.Errorf("failed to read prompt file %s: %v", filename, fs.ErrNotExist)
+}
+
+// SavePromptContent saves the content as personal prompt of the given action type
+// Built-in and team prompts are read-only, saving them creates a personal copy
+func SavePromptContent(actionType, content string) error {
+	filename := promptFileName(actionType)
+
+	// Ensure config directory exists
+	if err := os.MkdirAll(ConfigPath, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+
+	// Keep every saved version, a bad edit can be restored from the history
+	if err := snapshotPrompt(actionType, content); err != nil {
+		fmt.Printf("Warning: prompt history of %s not updated: %v\n", actionType, err)
+	}
+
+	err := os.WriteFile(PromptFile(actionType), []byte(content), 0644)
+	InvalidatePrompts()
+	if err != nil {
+		return fmt.Errorf("failed to write prompt file %s: %v", filename, err)
+	}
+
+	return nil
+}
+
+// removePersonalPrompt deletes the personal prompt file of an action, its content stays in the history
+func removePersonalPrompt(actionType, content string) error {
+	if err := snapshotPrompt(actionType, content); err != nil {
+		fmt.Printf("Warning: prompt history of %s not updated: %v\n", actionType, err)
+	}
+	err := os.Remove(PromptFile(actionType))
+	InvalidatePrompts()
+	if err != nil {
+		return fmt.Errorf("failed to delete prompt file of %s: %v", actionType, err)
+	}
+	return nil
+}
+
+// BuiltInPrompt returns the embedded default prompt of an action
+func BuiltInPrompt(actionType string) (string, error) {
+	if builtInPrompts == nil {
+		return "", fmt.Errorf("no built-in prompts")
+	}
+	content, err := fs.ReadFile(builtInPrompts, promptFileName(actionType))
+	if err != nil {
+		return "", fmt.Errorf("no built-in prompt for action %s", actionType)
+	}
+	return string(content), nil
+}
@@ -0,0 +1,236 @@
+package jobs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/megaproaktiv/audionote-config/workspace"
+)
+
+// Stage is the pipeline step a job has reached
+type Stage string
+
+const (
+	StageStaged       Stage = "staged"
+	StageUploaded     Stage = "uploaded"
+	StageTranscribing Stage = "transcribing"
+	StageTranscribed  Stage = "transcribed"
+	StageCompleted    Stage = "completed"
+	StageFailed       Stage = "failed"
+)
+
+// Job is the persisted state of one processing run
+type Job struct {
+	ID             string    `json:"id"`
+	InputFile      string    `json:"input_file"`
+	FileHash       string    `json:"file_hash"`
+	Bucket         string    `json:"bucket"`
+	Prefix         string    `json:"prefix,omitempty"`
+	S3Key          string    `json:"s3_key,omitempty"`
+	TranscribeJob  string    `json:"transcribe_job,omitempty"`
+	TranscriptFile string    `json:"transcript_file,omitempty"`
+	Language       string    `json:"language"`
+	Action         string    `json:"action"`
+	Stage          Stage     `json:"stage"`
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// SpeakerLabels tells the speakers apart in the transcript, set when the prompt uses them
+	SpeakerLabels bool `json:"speaker_labels,omitempty"`
+	// Inputs are the values entered for the prompt inputs when the job was started
+	Inputs map[string]string `json:"inputs,omitempty"`
+}
+
+// MaxFinishedJobs is the number of completed and failed jobs kept in the store, the oldest are pruned
+const MaxFinishedJobs = 50
+
+var mutex sync.Mutex
+
+// storePath returns the location of the job store file
+func storePath() string {
+	return filepath.Join(workspace.Dir(), "jobs.json")
+}
+
+// New creates and persists a job for the given input file
+// bucket and prefix record where the job's objects are written in S3
+func New(inputFile, bucket, prefix, language, action string) (*Job, error) {
+	hash, err := HashFile(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash %s: %v", inputFile, err)
+	}
+	now := time.Now()
+	job := &Job{
+		ID:        fmt.Sprintf("%s-%d", hash[:12], now.Unix()),
+		InputFile: inputFile,
+		FileHash:  hash,
+		Bucket:    bucket,
+		Prefix:    prefix,
+		Language:  language,
+		Action:    action,
+		Stage:     StageStaged,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return job, Save(job)
+}
+
+// HashFile returns the hex encoded SHA-256 of a file's content
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SetStage moves the job to a new stage and persists it
+func (j *Job) SetStage(stage Stage) {
+	j.Stage = stage
+	if err := Save(j); err != nil {
+		fmt.Printf("Warning: could not save job %s: %v\n", j.ID, err)
+	}
+}
+
+// Fail marks the job as failed with the given error and persists it
+func (j *Job) Fail(err error) {
+	j.Error = err.Error()
+	j.SetStage(StageFailed)
+}
+
+// Incomplete reports whether the job can still be resumed
+func (j *Job) Incomplete() bool {
+	return j.Stage != StageCompleted && j.Stage != StageFailed
+}
+
+// List returns all persisted jobs, newest first
+func List() ([]*Job, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return load()
+}
+
+// ListIncomplete returns all jobs that have not completed, newest first
+func ListIncomplete() ([]*Job, error) {
+	all, err := List()
+	if err != nil {
+		return nil, err
+	}
+	var incomplete []*Job
+	for _, job := range all {
+		if job.Incomplete() {
+			incomplete = append(incomplete, job)
+		}
+	}
+	return incomplete, nil
+}
+
+// Save inserts or updates a job in the store
+func Save(job *Job) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	all, err := load()
+	if err != nil {
+		return err
+	}
+	job.UpdatedAt = time.Now()
+	replaced := false
+	for i, existing := range all {
+		if existing.ID == job.ID {
+			all[i] = job
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		all = append(all, job)
+	}
+	return store(prune(all))
+}
+
+// prune drops the oldest finished jobs above MaxFinishedJobs, incomplete jobs are kept to be resumed
+func prune(all []*Job) []*Job {
+	sort.Slice(all, func(a, b int) bool {
+		return all[a].CreatedAt.After(all[b].CreatedAt)
+	})
+	var kept []*Job
+	finished := 0
+	for _, job := range all {
+		if !job.Incomplete() {
+			finished++
+			if finished > MaxFinishedJobs {
+				continue
+			}
+		}
+		kept = append(kept, job)
+	}
+	return kept
+}
+
+// Remove deletes a job from the store
+func Remove(id string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	all, err := load()
+	if err != nil {
+		return err
+	}
+	var kept []*Job
+	for _, job := range all {
+		if job.ID != id {
+			kept = append(kept, job)
+		}
+	}
+	return store(kept)
+}
+
+// load reads the job store, a missing file is an empty store
+func load() ([]*Job, error) {
+	data, err := os.ReadFile(storePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job store: %v", err)
+	}
+	var all []*Job
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("failed to parse job store: %v", err)
+	}
+	sort.Slice(all, func(a, b int) bool {
+		return all[a].CreatedAt.After(all[b].CreatedAt)
+	})
+	return all, nil
+}
+
+// store writes the job store atomically
+func store(all []*Job) error {
+	if err := os.MkdirAll(workspace.Dir(), 0755); err != nil {
+		return fmt.Errorf("failed to create workspace directory: %v", err)
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := storePath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write job store: %v", err)
+	}
+	return os.Rename(tmp, storePath())
+}
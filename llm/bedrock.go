@@ -2,8 +2,9 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
@@ -11,20 +12,19 @@ import (
 	awsutil "github.com/megaproaktiv/audionote-config/aws"
 )
 
-func CallBedrock(prompt string, model string, awsProfile string) (string, error) {
-	fmt.Printf("Calling Bedrock model '%s'...\n", model)
-	// This simulates an API call to Bedrock.
-	input := "Processed result from Bedrock with prompt: " + prompt
-	
+// CallBedrock sends the rendered prompt unchanged to the model, system is sent as system prompt if it is not empty
+// region overrides the profile's default region, empty keeps it
+func CallBedrock(system string, prompt string, model string, awsProfile string, region string, params Params) (string, error) {
+	fmt.Printf("Calling Bedrock model '%s' with %s...\n", model, params)
+
 	ctx := context.TODO()
-	cfg, err := awsutil.LoadAndValidateAWSConfig(ctx, awsProfile)
+	session, err := awsutil.GetSession(ctx, awsProfile)
 	if err != nil {
 		fmt.Printf("AWS configuration error: %v\n", err)
 		return "", fmt.Errorf("AWS configuration error: %w", err)
 	}
-	
-	client := bedrockruntime.NewFromConfig(cfg)
-	result, err := Converse(ctx, client, input, model)
+
+	result, err := Converse(ctx, session.Bedrock(region), system, prompt, model, params)
 	if err != nil {
 		return "", err
 	}
@@ -32,10 +32,16 @@ func CallBedrock(prompt string, model string, awsProfile string) (string, error)
 }
 
 // Plain Converse
-func Converse(ctx context.Context, client *bedrockruntime.Client, input string, model string) (string, error) {
+func Converse(ctx context.Context, client *bedrockruntime.Client, system string, input string, model string, params Params) (string, error) {
 
 	converseInput := &bedrockruntime.ConverseInput{
-		ModelId: aws.String(model),
+		ModelId:         aws.String(model),
+		InferenceConfig: params.inferenceConfiguration(),
+	}
+	if system != "" {
+		converseInput.System = []types.SystemContentBlock{
+			&types.SystemContentBlockMemberText{Value: system},
+		}
 	}
 
 	// create user message
@@ -52,37 +58,42 @@ func Converse(ctx context.Context, client *bedrockruntime.Client, input string,
 
 	output, err := client.Converse(ctx, converseInput)
 
+	// Some models reject system prompts, they get it in front of the user message instead
+	var validation *types.ValidationException
+	if err != nil && system != "" && errors.As(err, &validation) && strings.Contains(strings.ToLower(validation.ErrorMessage()), "system") {
+		fmt.Printf("Model %s does not support system prompts, sending it with the prompt\n", model)
+		return Converse(ctx, client, "", system+"\n\n"+input, model, params)
+	}
+
 	if err != nil {
-		fmt.Println("Converse API Call", "error", err)
-		os.Exit(1)
+		return "", fmt.Errorf("converse API call failed: %w", err)
 	}
 
 	if output == nil {
-		fmt.Println("Empty response from Bedrock API")
-		os.Exit(2)
+		return "", errors.New("empty response from Bedrock API")
 	}
 
 	response, ok := output.Output.(*types.ConverseOutputMemberMessage)
 	if !ok {
-		fmt.Println("Unexpected response type from Bedrock API")
-		os.Exit(3)
+		return "", errors.New("unexpected response type from Bedrock API")
 	}
 
 	if len(response.Value.Content) == 0 {
-		fmt.Println("Empty content in Bedrock API response")
-		os.Exit(4)
+		return "", errors.New("empty content in Bedrock API response")
 	}
 
 	responseContentBlock := response.Value.Content[0]
 	text, ok := responseContentBlock.(*types.ContentBlockMemberText)
 	if !ok {
-		fmt.Println("Unexpected content block type in Bedrock API response")
-		os.Exit(5)
+		return "", errors.New("unexpected content block type in Bedrock API response")
 	}
 
 	if text.Value == "" {
-		fmt.Println("Empty text value in Bedrock API response")
-		os.Exit(6)
+		return "", errors.New("empty text value in Bedrock API response")
+	}
+
+	if output.StopReason == types.StopReasonMaxTokens {
+		fmt.Println("Warning: the model stopped at the max tokens limit, the result is truncated. Increase Max Tokens in the inference parameters")
 	}
 
 	return text.Value, nil
@@ -0,0 +1,249 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrock"
+	"github.com/aws/aws-sdk-go-v2/service/bedrock/types"
+	awsutil "github.com/megaproaktiv/audionote-config/aws"
+)
+
+// ModelInfo describes a Bedrock model or inference profile that can generate text
+type ModelInfo struct {
+	// ID is passed as model ID to Converse, for inference profiles the profile ID like "eu.anthropic..."
+	ID               string
+	Name             string
+	Provider         string
+	InputModalities  []string
+	OutputModalities []string
+	// ContextTokens is the size of the context window, 0 if unknown
+	ContextTokens int
+	// InferenceProfile is set for cross-region inference profiles
+	InferenceProfile bool
+	// FoundationModel is the ID of the model that runs the request
+	FoundationModel string
+}
+
+// Modalities returns the input and output modalities, e.g. "TEXT, IMAGE → TEXT"
+func (m ModelInfo) Modalities() string {
+	return strings.Join(m.InputModalities, ", ") + " → " + strings.Join(m.OutputModalities, ", ")
+}
+
+// Context returns the size of the context window, e.g. "200k tokens"
+func (m ModelInfo) Context() string {
+	switch {
+	case m.ContextTokens == 0:
+		return "unknown"
+	case m.ContextTokens >= 1000000 && m.ContextTokens%1000000 == 0:
+		return fmt.Sprintf("%dM tokens", m.ContextTokens/1000000)
+	default:
+		return fmt.Sprintf("%dk tokens", m.ContextTokens/1000)
+	}
+}
+
+// contextWindows are the context sizes of the model families, the API does not return them
+// The first matching prefix of the model ID without inference profile prefix wins
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"anthropic.claude-v2", 100000},
+	{"anthropic.claude-instant", 100000},
+	{"anthropic.claude", 200000},
+	{"amazon.nova-premier", 1000000},
+	{"amazon.nova-micro", 128000},
+	{"amazon.nova", 300000},
+	{"amazon.titan-text-premier", 32000},
+	{"amazon.titan-text", 8000},
+	{"meta.llama3-8b", 8000},
+	{"meta.llama3-70b", 8000},
+	{"meta.llama", 128000},
+	{"mistral.mistral-large-2407", 128000},
+	{"mistral.pixtral", 128000},
+	{"mistral", 32000},
+	{"cohere.command-r", 128000},
+	{"cohere.command", 4000},
+	{"ai21.jamba", 256000},
+	{"deepseek", 128000},
+	{"writer.palmyra", 128000},
+}
+
+// ContextTokens returns the known context window of a model or inference profile ID, 0 if unknown
+func ContextTokens(modelID string) int {
+	id := FoundationModelID(modelID)
+	for _, window := range contextWindows {
+		if strings.HasPrefix(id, window.prefix) {
+			return window.tokens
+		}
+	}
+	return 0
+}
+
+// FoundationModelID strips the geography of an inference profile ID, "eu.amazon.nova-pro-v1:0"
+// becomes "amazon.nova-pro-v1:0". Foundation model IDs are returned unchanged
+func FoundationModelID(modelID string) string {
+	prefix, rest, found := strings.Cut(modelID, ".")
+	if found && strings.Contains(rest, ".") {
+		switch prefix {
+		case "us", "eu", "apac", "us-gov", "ca", "jp", "au", "global":
+			return rest
+		}
+	}
+	return modelID
+}
+
+// ListModels returns the text generating foundation models that can be invoked on demand
+// and the inference profiles of a region, sorted by provider and name
+// The cached access status is dropped, access granted meanwhile shows up
+func ListModels(awsProfile string, region string) ([]ModelInfo, error) {
+	accessMu.Lock()
+	clear(accessCache)
+	accessMu.Unlock()
+
+	ctx := context.TODO()
+	session, err := awsutil.GetSession(ctx, awsProfile)
+	if err != nil {
+		return nil, fmt.Errorf("AWS configuration error: %w", err)
+	}
+	client := session.BedrockControl(region)
+
+	foundation, err := client.ListFoundationModels(ctx, &bedrock.ListFoundationModelsInput{
+		ByOutputModality: types.ModelModalityText,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list foundation models: %w", err)
+	}
+
+	var models []ModelInfo
+	byID := map[string]types.FoundationModelSummary{}
+	for _, summary := range foundation.ModelSummaries {
+		id := aws.ToString(summary.ModelId)
+		byID[id] = summary
+		if summary.ModelLifecycle != nil && summary.ModelLifecycle.Status != types.FoundationModelLifecycleStatusActive {
+			continue
+		}
+		// Models without on demand inference are only reachable through an inference profile
+		onDemand := false
+		for _, inferenceType := range summary.InferenceTypesSupported {
+			if inferenceType == types.InferenceTypeOnDemand {
+				onDemand = true
+			}
+		}
+		if !onDemand {
+			continue
+		}
+		models = append(models, modelInfo(id, summary))
+	}
+
+	paginator := bedrock.NewListInferenceProfilesPaginator(client, &bedrock.ListInferenceProfilesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			// Inference profiles are not available in every region and need their own permission
+			fmt.Printf("Could not list inference profiles in %s: %v\n", region, err)
+			break
+		}
+		for _, profile := range page.InferenceProfileSummaries {
+			if profile.Status != types.InferenceProfileStatusActive || len(profile.Models) == 0 {
+				continue
+			}
+			_, foundationID, _ := strings.Cut(aws.ToString(profile.Models[0].ModelArn), "foundation-model/")
+			summary, ok := byID[foundationID]
+			if !ok {
+				// Not a text model
+				continue
+			}
+			info := modelInfo(aws.ToString(profile.InferenceProfileId), summary)
+			info.Name = aws.ToString(profile.InferenceProfileName)
+			info.InferenceProfile = true
+			info.FoundationModel = foundationID
+			models = append(models, info)
+		}
+	}
+
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].Provider != models[j].Provider {
+			return models[i].Provider < models[j].Provider
+		}
+		return models[i].ID < models[j].ID
+	})
+	fmt.Printf("Found %d Bedrock text models in %s\n", len(models), region)
+	return models, nil
+}
+
+// modelInfo converts a foundation model summary
+func modelInfo(id string, summary types.FoundationModelSummary) ModelInfo {
+	info := ModelInfo{
+		ID:              id,
+		Name:            aws.ToString(summary.ModelName),
+		Provider:        aws.ToString(summary.ProviderName),
+		ContextTokens:   ContextTokens(id),
+		FoundationModel: aws.ToString(summary.ModelId),
+	}
+	for _, modality := range summary.InputModalities {
+		info.InputModalities = append(info.InputModalities, string(modality))
+	}
+	for _, modality := range summary.OutputModalities {
+		info.OutputModalities = append(info.OutputModalities, string(modality))
+	}
+	return info
+}
+
+var (
+	accessMu    sync.Mutex
+	accessCache = map[string]string{}
+)
+
+// ModelAccess returns whether the account has access to the foundation model behind a model
+// or inference profile ID, e.g. "granted" or "not authorized, agreement pending"
+// The result is cached per region until the models are listed again
+func ModelAccess(modelID string, awsProfile string, region string) (string, error) {
+	foundationID := FoundationModelID(modelID)
+	key := awsProfile + "|" + region + "|" + foundationID
+	accessMu.Lock()
+	status, ok := accessCache[key]
+	accessMu.Unlock()
+	if ok {
+		return status, nil
+	}
+
+	ctx := context.TODO()
+	session, err := awsutil.GetSession(ctx, awsProfile)
+	if err != nil {
+		return "", fmt.Errorf("AWS configuration error: %w", err)
+	}
+	availability, err := session.BedrockControl(region).GetFoundationModelAvailability(ctx, &bedrock.GetFoundationModelAvailabilityInput{
+		ModelId: aws.String(foundationID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get access status of %s: %w", foundationID, err)
+	}
+
+	var missing []string
+	if availability.AuthorizationStatus != types.AuthorizationStatusAuthorized {
+		missing = append(missing, "not authorized")
+	}
+	if availability.EntitlementAvailability != types.EntitlementAvailabilityAvailable {
+		missing = append(missing, "not entitled")
+	}
+	if availability.RegionAvailability != types.RegionAvailabilityAvailable {
+		missing = append(missing, "not available in region")
+	}
+	if availability.AgreementAvailability != nil && availability.AgreementAvailability.Status != types.AgreementStatusAvailable {
+		missing = append(missing, "agreement "+strings.ToLower(strings.ReplaceAll(string(availability.AgreementAvailability.Status), "_", " ")))
+	}
+	status = "granted"
+	if len(missing) > 0 {
+		status = strings.Join(missing, ", ")
+	}
+
+	accessMu.Lock()
+	accessCache[key] = status
+	accessMu.Unlock()
+	return status, nil
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	awsutil "github.com/megaproaktiv/audionote-config/aws"
+)
+
+// DefaultModel is the Bedrock model used when none is configured
+const DefaultModel = "anthropic.claude-3-5-sonnet-20240620-v1:0"
+
+// KnownModels are offered as suggestions when selecting a Bedrock model
+// Newer models may need an inference profile ID like "us." or "eu." in front of the model ID
+var KnownModels = []string{
+	DefaultModel,
+	"anthropic.claude-3-5-sonnet-20241022-v2:0",
+	"anthropic.claude-3-haiku-20240307-v1:0",
+	"us.anthropic.claude-3-7-sonnet-20250219-v1:0",
+	"eu.anthropic.claude-3-7-sonnet-20250219-v1:0",
+	"amazon.nova-pro-v1:0",
+	"amazon.nova-lite-v1:0",
+	"meta.llama3-70b-instruct-v1:0",
+	"mistral.mistral-large-2402-v1:0",
+}
+
+// TestModel sends a minimal request to the model to verify the account can invoke it
+// region overrides the profile's default region, empty keeps it
+func TestModel(model string, awsProfile string, region string) error {
+	ctx := context.TODO()
+	session, err := awsutil.GetSession(ctx, awsProfile)
+	if err != nil {
+		return fmt.Errorf("AWS configuration error: %w", err)
+	}
+
+	client := session.Bedrock(region)
+	fmt.Printf("Testing Bedrock model '%s'...\n", model)
+	_, err = client.Converse(ctx, &bedrockruntime.ConverseInput{
+		ModelId: aws.String(model),
+		Messages: []types.Message{{
+			Role: types.ConversationRoleUser,
+			Content: []types.ContentBlock{
+				&types.ContentBlockMemberText{Value: "Reply with OK."},
+			},
+		}},
+		InferenceConfig: &types.InferenceConfiguration{
+			MaxTokens: aws.Int32(10),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("model %s cannot be invoked: %w", model, err)
+	}
+	fmt.Printf("Bedrock model '%s' is accessible\n", model)
+	return nil
+}
@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// MaxStopSequences is the number of stop sequences the Converse API accepts
+const MaxStopSequences = 4
+
+// Params are the inference parameters of a request, nil and empty values keep the model default
+type Params struct {
+	Temperature   *float32
+	TopP          *float32
+	MaxTokens     *int32
+	StopSequences []string
+}
+
+// String describes the parameters for the log, e.g. "temperature 0.2, max tokens 4096"
+func (p Params) String() string {
+	var parts []string
+	if p.Temperature != nil {
+		parts = append(parts, fmt.Sprintf("temperature %g", *p.Temperature))
+	}
+	if p.TopP != nil {
+		parts = append(parts, fmt.Sprintf("top-p %g", *p.TopP))
+	}
+	if p.MaxTokens != nil {
+		parts = append(parts, fmt.Sprintf("max tokens %d", *p.MaxTokens))
+	}
+	if len(p.StopSequences) > 0 {
+		parts = append(parts, fmt.Sprintf("stop sequences %q", p.StopSequences))
+	}
+	if len(parts) == 0 {
+		return "model defaults"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// inferenceConfiguration returns the Converse inference configuration, nil if all parameters are defaults
+func (p Params) inferenceConfiguration() *types.InferenceConfiguration {
+	if p.Temperature == nil && p.TopP == nil && p.MaxTokens == nil && len(p.StopSequences) == 0 {
+		return nil
+	}
+	return &types.InferenceConfiguration{
+		Temperature:   p.Temperature,
+		TopP:          p.TopP,
+		MaxTokens:     p.MaxTokens,
+		StopSequences: p.StopSequences,
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"maps"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -21,10 +24,13 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	awsutil "github.com/megaproaktiv/audionote-config/aws"
 	"github.com/megaproaktiv/audionote-config/configuration"
+	"github.com/megaproaktiv/audionote-config/jobs"
 	"github.com/megaproaktiv/audionote-config/llm"
 	"github.com/megaproaktiv/audionote-config/panel"
 	"github.com/megaproaktiv/audionote-config/translate"
+	"github.com/megaproaktiv/audionote-config/workspace"
 )
 
 //go:embed config-default/*
@@ -110,19 +116,47 @@ func (oc *OutputCapture) Close() {
 	oc.pipeReader.Close()
 }
 
+// promptData returns the template variables of a job's prompt
+// Duration and speakers are read from the transcription result, they are 0 if it is not available
+func promptData(job *jobs.Job, transcript string) configuration.PromptData {
+	data := configuration.PromptData{
+		Transcript: transcript,
+		FileName:   filepath.Base(job.InputFile),
+		RecordedAt: job.CreatedAt,
+		Language:   job.Language,
+	}
+	if info, err := os.Stat(job.InputFile); err == nil {
+		data.RecordedAt = info.ModTime()
+	}
+	if job.TranscriptFile != "" {
+		details, err := translate.ReadTranscriptDetails(job.TranscriptFile)
+		if err != nil {
+			fmt.Printf("Could not read transcript details from %s: %v\n", job.TranscriptFile, err)
+		} else {
+			data.DurationMinutes = int(math.Round(details.Duration.Minutes()))
+			data.Speakers = details.Speakers
+		}
+	}
+	return data
+}
+
 // checkForExistingTranscript checks if a transcript already exists for the given audio file
-func checkForExistingTranscript(audioFilePath, bucket, language string) string {
+// It returns the transcript text and the file it was read from
+func checkForExistingTranscript(audioFilePath, bucket, language string) (string, string) {
 	// Generate the expected job name based on the audio file
 	fileName := filepath.Base(audioFilePath)
 	baseName := strings.TrimSuffix(fileName, filepath.Ext(fileName))
 
-	// Check for existing transcript files in the local directory
+	stagedName := workspace.SanitizeName(fileName)
+	stagedBaseName := strings.TrimSuffix(stagedName, filepath.Ext(stagedName))
+
+	// Check for existing transcript files in the workspace output directory
 	// Look for files matching multiple patterns to catch all variations:
-	// 1. summary/output/{baseName}-DMIN-*.json (original filename)
-	// 2. summary/output/{baseName}_Copy-DMIN-*.json (filename with _Copy suffix)
-	// 3. summary/output/{baseName}_copy.{ext}-DMIN-*.json (filename with _copy suffix and extension)
-	outputDir := "summary/output"
-	pattern1 := filepath.Join(outputDir, baseName+"-DMIN-*.json")
+	// 1. {stagedBaseName}-DMIN-*.json (sanitized filename as staged in the workspace)
+	// 2. {baseName}_Copy-DMIN-*.json (filename with _Copy suffix)
+	// 3. {baseName}_copy.{ext}-DMIN-*.json (filename with _copy suffix and extension)
+	outputDir := workspace.OutputDir()
+	pattern1 := filepath.Join(outputDir, stagedBaseName+"-DMIN-*.json")
 	pattern2 := filepath.Join(outputDir, baseName+"_Copy-DMIN-*.json")
 	pattern3 := filepath.Join(outputDir, baseName+"_copy.*-DMIN-*.json")
 
@@ -175,7 +209,7 @@ func checkForExistingTranscript(audioFilePath, bucket, language string) string {
 			data, err := os.ReadFile(latestFile)
 			if err != nil {
 				fmt.Printf("Error reading existing transcript: %v\n", err)
-				return ""
+				return "", ""
 			}
 
 			// Parse the JSON to extract the transcript text
@@ -189,18 +223,28 @@ func checkForExistingTranscript(audioFilePath, bucket, language string) string {
 
 			if err := json.Unmarshal(data, &transcriptResp); err != nil {
 				fmt.Printf("Error parsing existing transcript JSON: %v\n", err)
-				return ""
+				return "", ""
 			}
 
 			if len(transcriptResp.Results.Transcripts) > 0 {
 				fmt.Printf("Successfully loaded existing transcript (%d characters)\n", len(transcriptResp.Results.Transcripts[0].Transcript))
-				return transcriptResp.Results.Transcripts[0].Transcript
+				return transcriptResp.Results.Transcripts[0].Transcript, latestFile
 			}
 		}
 	}
 
 	fmt.Printf("No existing transcript found for %s\n", audioFilePath)
-	return ""
+	return "", ""
+}
+
+// storageForJob returns the S3 storage settings for a job
+// Bucket and prefix are taken from the job, so resumed jobs find their objects
+func storageForJob(config *configuration.Config, job *jobs.Job) translate.Storage {
+	prefix := job.Prefix
+	if prefix == "" {
+		prefix = config.S3Prefix
+	}
+	return translate.NewStorage(job.Bucket, prefix, config.S3SSE, config.S3KMSKeyID)
 }
 
 func main() {
@@ -219,6 +263,15 @@ func main() {
 	//--------------------------------------------------------------
 	config := configuration.InitConfigWithFS(defaultConfigFS)
 
+	// Expired SSO sessions are renewed with a login dialog before AWS calls,
+	// roles that require MFA ask for the code in a dialog
+	awsutil.SSOLoginHandler = p.EnsureSSOLogin
+	awsutil.MFATokenHandler = p.PromptMFAToken
+	awsutil.SetEndpoints(panel.AWSEndpoints(config))
+	if err := awsutil.SetNetwork(panel.AWSNetwork(config)); err != nil {
+		fmt.Printf("Error in network settings, using the environment: %v\n", err)
+	}
+
 	//--------------------------------------------------------------
 	// Create output field for stdout capture
 	//--------------------------------------------------------------
@@ -238,6 +291,14 @@ func main() {
 
 	// Note: Size will be set when creating the scroll container
 
+	// Widgets showing configuration values, refreshed after the setup wizard
+	var outputDirectoryLabel *widget.Label
+	onSetupFinished := func() {
+		if outputDirectoryLabel != nil {
+			outputDirectoryLabel.SetText(fmt.Sprintf("Output Directory: %s", filepath.Dir(config.OutputPath)))
+		}
+	}
+
 	//--------------------------------------------------------------
 	// Create application menu
 	//--------------------------------------------------------------
@@ -252,11 +313,34 @@ func main() {
 			p.OutputField = outputField
 			p.ShowConfigDialog(config)
 		}),
+		fyne.NewMenuItem("Setup Wizard...", func() {
+			p.ShowSetupWizard(config, onSetupFinished)
+		}),
+		fyne.NewMenuItem("Global Context...", func() {
+			p.ShowGlobalContextDialog()
+		}),
+		fyne.NewMenuItem("Clean Workspace...", func() {
+			p.ShowCleanWorkspaceDialog()
+		}),
+	)
+
+	jobsMenu := fyne.NewMenu("Jobs",
+		fyne.NewMenuItem("Transcribe Jobs...", func() {
+			p.ShowTranscribeJobBrowser(config)
+		}),
 	)
 
-	mainMenu := fyne.NewMainMenu(configMenu, aboutMenu)
+	mainMenu := fyne.NewMainMenu(configMenu, jobsMenu, aboutMenu)
 	w.SetMainMenu(mainMenu)
 
+	//--------------------------------------------------------------
+	// Merge built-in prompts of a new app version
+	//--------------------------------------------------------------
+	defaultUpdates, err := configuration.SyncDefaults()
+	if err != nil {
+		fmt.Printf("Error merging built-in prompts: %v\n", err)
+	}
+
 	//--------------------------------------------------------------
 	// Load action types from prompt files
 	//--------------------------------------------------------------
@@ -279,8 +363,39 @@ func main() {
 	promptEditor.Wrapping = fyne.TextWrapWord
 	promptEditor.SetPlaceHolder("Select an action type to load its prompt content...")
 
+	// Template errors are shown below the editor while typing
+	promptStatus := widget.NewLabel("")
+	promptStatus.Wrapping = fyne.TextWrapWord
+	promptEditor.OnChanged = func(content string) {
+		placed, err := configuration.CheckPrompt(config.LastActionType, content)
+		switch {
+		case err != nil:
+			promptStatus.SetText("✗ " + err.Error())
+		case placed:
+			promptStatus.SetText("✓ The transcript is inserted at {{.Transcript}}")
+		default:
+			promptStatus.SetText("✓ The transcript is appended after the prompt")
+		}
+	}
+
+	// Prompt editor label, it shows where the prompt comes from
+	promptLabel := widget.NewLabel("Prompt Editor:")
+	promptLabel.TextStyle.Bold = true
+	showPromptSource := func(actionType string) {
+		entry, err := configuration.FindPrompt(actionType)
+		switch {
+		case err != nil:
+			promptLabel.SetText("Prompt Editor:")
+		case entry.Source.ReadOnly():
+			promptLabel.SetText(fmt.Sprintf("Prompt Editor: %s (%s, saving creates a personal copy)", actionType, entry.Describe()))
+		default:
+			promptLabel.SetText(fmt.Sprintf("Prompt Editor: %s (%s)", actionType, entry.Describe()))
+		}
+	}
+
 	// Function to load prompt content
 	loadPromptContent := func(actionType string) {
+		showPromptSource(actionType)
 		content, err := configuration.LoadPromptContent(actionType)
 		if err != nil {
 			promptEditor.SetText(fmt.Sprintf("Error loading prompt for '%s': %v", actionType, err))
@@ -291,16 +406,41 @@ func main() {
 		}
 	}
 
+	// applyPromptLanguage selects the transcript language declared in the front matter of a prompt
+	var applyPromptLanguage func(actionType string)
+
 	//--------------------------------------------------------------
 	// Create action type selector
 	//--------------------------------------------------------------
+	// The selector shows the actions in display order with their group,
+	// actionLabels maps the shown labels back to the actions, labelOf the actions to their labels
+	actionLabels := map[string]string{}
+	labelOf := map[string]string{}
+	actionOptions := func(actions []string) []string {
+		clear(actionLabels)
+		labelOf = config.ActionLabels(actions)
+		var options []string
+		for _, action := range config.OrderActions(actions) {
+			label := labelOf[action]
+			actionLabels[label] = action
+			options = append(options, label)
+		}
+		return options
+	}
 	actionSelect := widget.NewSelect(
-		actionTypes,
-		func(value string) {
+		actionOptions(actionTypes),
+		func(label string) {
+			value, ok := actionLabels[label]
+			if !ok {
+				value = label
+			}
 			fmt.Printf("Action selected: %s\n", value)
 			config.LastActionType = value
 			// Load the corresponding prompt content
 			loadPromptContent(value)
+			if applyPromptLanguage != nil {
+				applyPromptLanguage(value)
+			}
 		},
 	)
 
@@ -309,7 +449,7 @@ func main() {
 	if defaultAction == "" || !configuration.Contains(actionTypes, defaultAction) {
 		defaultAction = actionTypes[0]
 	}
-	actionSelect.SetSelected(defaultAction)
+	actionSelect.SetSelected(labelOf[defaultAction])
 	config.LastActionType = defaultAction
 
 	//--------------------------------------------------------------
@@ -331,6 +471,19 @@ func main() {
 		config.LastLanguage = "en-US"
 	}
 
+	applyPromptLanguage = func(actionType string) {
+		prompt, err := configuration.LoadPrompt(actionType)
+		if err != nil || prompt.Settings.Language == "" {
+			return
+		}
+		if !configuration.Contains(languageSelect.Options, prompt.Settings.Language) {
+			languageSelect.Options = append(languageSelect.Options, prompt.Settings.Language)
+		}
+		fmt.Printf("Prompt of %s selects language %s\n", actionType, prompt.Settings.Language)
+		languageSelect.SetSelected(prompt.Settings.Language)
+	}
+	applyPromptLanguage(config.LastActionType)
+
 	//--------------------------------------------------------------
 	// Create file selector for audio files
 	//--------------------------------------------------------------
@@ -395,7 +548,6 @@ func main() {
 	// Create output path selector
 	//--------------------------------------------------------------
 	var outputPathSelector *widget.Button
-	var outputDirectoryLabel *widget.Label
 	outputPathSelector = widget.NewButton("Select Output Path", func() {
 		// Store current directory to restore later
 		currentDir, _ := os.Getwd()
@@ -452,33 +604,42 @@ func main() {
 			newActionTypes = []string{"blog", "paper", "requirements", "call-to-action"} // fallback
 		}
 
-		// Update the select widget options
-		actionSelect.Options = newActionTypes
+		// Update the select widget options, labels change with groups and prompt sources
+		actionSelect.Options = actionOptions(newActionTypes)
 
 		// If current selection is no longer valid, select the first option
-		if !configuration.Contains(newActionTypes, actionSelect.Selected) {
-			if len(newActionTypes) > 0 {
-				actionSelect.SetSelected(newActionTypes[0])
-				config.LastActionType = newActionTypes[0]
-				loadPromptContent(newActionTypes[0])
-			}
+		if !configuration.Contains(newActionTypes, config.LastActionType) {
+			first := config.OrderActions(newActionTypes)[0]
+			actionSelect.SetSelected(labelOf[first])
+			config.LastActionType = first
+			loadPromptContent(first)
+		} else {
+			actionSelect.Selected = labelOf[config.LastActionType]
+			actionSelect.Refresh()
+			showPromptSource(config.LastActionType)
 		}
 
 		fmt.Printf("Refreshed action types: %v\n", newActionTypes)
 	}
+	p.RefreshActions = refreshActionTypes
 
 	//--------------------------------------------------------------
 	// Create prompt management buttons
 	//--------------------------------------------------------------
 	// Create save button for prompt editor
 	savePromptButton := widget.NewButtonWithIcon("Save Prompt", theme.DocumentSaveIcon(), func() {
-		currentAction := actionSelect.Selected
+		currentAction := config.LastActionType
 		if currentAction == "" {
 			dialog.ShowError(fmt.Errorf("no action type selected"), w)
 			return
 		}
 
 		content := promptEditor.Text
+		// Reject prompts whose front matter or template would fail the run
+		if _, err := configuration.CheckPrompt(currentAction, content); err != nil {
+			dialog.ShowError(fmt.Errorf("prompt not saved: %v", err), w)
+			return
+		}
 		err := configuration.SavePromptContent(currentAction, content)
 		if err != nil {
 			dialog.ShowError(fmt.Errorf("failed to save prompt: %v", err), w)
@@ -486,13 +647,58 @@ func main() {
 		} else {
 			dialog.ShowInformation("Success", fmt.Sprintf("Prompt for '%s' saved successfully!", currentAction), w)
 			fmt.Printf("Successfully saved prompt for action type: %s\n", currentAction)
+			refreshActionTypes()
+			applyPromptLanguage(currentAction)
 		}
 	})
 
+	// Create button for the inference parameters of the selected action
+	inferenceButton := widget.NewButtonWithIcon("Parameters...", theme.SettingsIcon(), func() {
+		if config.LastActionType == "" {
+			dialog.ShowError(fmt.Errorf("no action type selected"), w)
+			return
+		}
+		p.ShowActionInferenceDialog(config, config.LastActionType)
+	})
+
+	// Create button for the saved versions of the selected prompt
+	historyButton := widget.NewButtonWithIcon("History...", theme.HistoryIcon(), func() {
+		if config.LastActionType == "" {
+			dialog.ShowError(fmt.Errorf("no action type selected"), w)
+			return
+		}
+		currentAction := config.LastActionType
+		p.ShowPromptHistoryDialog(currentAction, promptEditor.Text, func(content string) {
+			promptEditor.SetText(content)
+			refreshActionTypes()
+			applyPromptLanguage(currentAction)
+		})
+	})
+
+	// Create button to go back to the built-in prompt of the selected action
+	restoreDefaultButton := widget.NewButtonWithIcon("Restore Default", theme.ContentUndoIcon(), func() {
+		if config.LastActionType == "" {
+			dialog.ShowError(fmt.Errorf("no action type selected"), w)
+			return
+		}
+		currentAction := config.LastActionType
+		p.ShowRestoreDefaultDialog(currentAction, promptEditor.Text, func() {
+			loadPromptContent(currentAction)
+			refreshActionTypes()
+			applyPromptLanguage(currentAction)
+		})
+	})
+
 	// Create refresh button for action types
 	refreshActionButton := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
-		refreshActionTypes()
-		dialog.ShowInformation("Refreshed", "Action types refreshed from directory", w)
+		p.SyncTeamPrompts(config, func(err error) {
+			refreshActionTypes()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("team prompts not synced: %v", err), w)
+				return
+			}
+			dialog.ShowInformation("Refreshed", "Action types refreshed from directory", w)
+		})
 	})
 
 	// Create new action button
@@ -503,18 +709,8 @@ func main() {
 
 		// Validate action name (no spaces, only alphanumeric and hyphens)
 		actionNameEntry.OnChanged = func(text string) {
-			// Remove spaces and special characters
-			cleanText := strings.ReplaceAll(text, " ", "-")
-			cleanText = strings.ToLower(cleanText)
-			// Keep only alphanumeric and hyphens
-			var result strings.Builder
-			for _, char := range cleanText {
-				if (char >= 'a' && char <= 'z') || (char >= '0' && char <= '9') || char == '-' {
-					result.WriteRune(char)
-				}
-			}
-			if result.String() != text {
-				actionNameEntry.SetText(result.String())
+			if clean := configuration.CleanActionName(text); clean != text {
+				actionNameEntry.SetText(clean)
 			}
 		}
 
@@ -561,7 +757,7 @@ Please process the following audio transcript and create a %s:
 					} else {
 						// Refresh the action types and select the new one
 						refreshActionTypes()
-						actionSelect.SetSelected(actionName)
+						actionSelect.SetSelected(labelOf[actionName])
 						config.LastActionType = actionName
 						loadPromptContent(actionName)
 
@@ -577,9 +773,28 @@ Please process the following audio transcript and create a %s:
 		confirmDialog.Show()
 	})
 
-	// Prompt editor label
-	promptLabel := widget.NewLabel("Prompt Editor:")
-	promptLabel.TextStyle.Bold = true
+	// Create button to rename, duplicate, delete, group and order the actions
+	manageActionsButton := widget.NewButtonWithIcon("", theme.ListIcon(), func() {
+		p.ShowManageActionsDialog(config,
+			func() []string {
+				actions, err := configuration.LoadPromptFiles()
+				if err != nil {
+					fmt.Printf("Error loading prompt files: %v\n", err)
+				}
+				return actions
+			},
+			func(selected string, reload bool) {
+				refreshActionTypes()
+				switch {
+				case selected == "":
+				case selected != config.LastActionType:
+					actionSelect.SetSelected(labelOf[selected])
+				case reload:
+					loadPromptContent(selected)
+				}
+			},
+		)
+	})
 
 	//--------------------------------------------------------------
 	// Create result display field
@@ -618,115 +833,264 @@ Please process the following audio transcript and create a %s:
 	p.PromptEditor = promptEditor
 	p.ResultField = resultField
 	p.SavePromptButton = savePromptButton
+	p.InferenceButton = inferenceButton
+	p.HistoryButton = historyButton
+	p.RestoreDefaultButton = restoreDefaultButton
+	p.PromptStatus = promptStatus
 	p.CopyResultButton = copyResultButton
 	rightPanel := p.RightPanel()
 
 	//--------------------------------------------------------------
 	// Create start button and processing logic
 	//--------------------------------------------------------------
-	// Create the start button with Material Design microphone icon
-	// Using emoji + built-in icon for better compatibility
 	var startButton *widget.Button
-	startButton = widget.NewButtonWithIcon("🎤 Start", theme.VolumeUpIcon(), func() {
-		action := actionSelect.Selected
-		language := languageSelect.Selected
-
-		if selectedFilePath == "" {
-			dialog.ShowInformation("No File Selected", "Please select an audio file first.", w)
-			return
-		}
-
-		// Save current configuration
-		config.Save()
 
-		fmt.Printf("Starting process with Action: %s, Language: %s, File: %s\n", action, language, selectedFilePath)
+	// runJob processes a job from the stage it has reached: transcription, then LLM processing
+	runJob := func(job *jobs.Job) {
+		fyne.Do(func() {
+			startButton.Disable()
+			progressBar.SetValue(float64(10) / 100.0)
+		})
+		ctx := context.Background()
 
-		//--------------------------------------------------------------
-		// Start processing
-		//--------------------------------------------------------------
-		go func() {
-			fyne.Do(func() {
-				startButton.Disable()
-				progressBar.SetValue(float64(10) / 100.0)
-			})
-			ctx := context.Background()
+		var transcript string
+		if job.Stage == jobs.StageStaged {
 			// Check if transcript already exists
 			fmt.Printf("Checking for existing transcript...\n")
-			existingTranscript := checkForExistingTranscript(selectedFilePath, config.S3Bucket, language)
-
-			var transcript string
+			existingTranscript, transcriptFile := checkForExistingTranscript(job.InputFile, job.Bucket, job.Language)
 			if existingTranscript != "" {
 				fmt.Printf("Found existing transcript, skipping transcription process\n")
 				transcript = existingTranscript
+				job.TranscriptFile = transcriptFile
+				job.SetStage(jobs.StageTranscribed)
+			}
+		}
+
+		if transcript == "" {
+			fmt.Printf("Transcribing job %s (stage: %s) with language: %s\n", job.ID, job.Stage, job.Language)
+			fyne.Do(func() {
+				progressBar.SetValue(float64(20) / 100.0)
+			})
+			awsProfile := config.AWSProfile
+			region, err := translate.InitClient(ctx, awsProfile, job.Bucket, config.BucketRegion(job.Bucket))
+			if err != nil {
 				fyne.Do(func() {
-					progressBar.SetValue(float64(50) / 100.0)
-				})
-			} else {
-				fmt.Printf("No existing transcript found, starting transcription with language: %s\n", language)
-				fyne.Do(func() {
-					progressBar.SetValue(float64(20) / 100.0)
+					progressBar.SetValue(float64(0.0))
+					fmt.Printf("Could not initialize AWS clients for profile %s: %v\n", awsProfile, err)
+					startButton.Enable()
 				})
-				awsProfile := config.AWSProfile
-				err = translate.InitClient(ctx, awsProfile)
-				if err != nil {
-					fyne.Do(func() {
-						progressBar.SetValue(float64(0.0))
-						fmt.Println("Could not load AWS profile: ", awsProfile)
-						startButton.Enable()
-					})
+				return
+			}
+			fyne.Do(func() {
+				if job.Bucket == config.S3Bucket && config.S3BucketRegion != region {
+					// Cache the bucket region, so it is looked up only once
+					config.S3BucketRegion = region
+					config.Save()
+				}
+				progressBar.SetValue(float64(30) / 100.0)
+			})
+			// The upload moves the progress bar from 30% to 40%
+			lastPercent := int64(-1)
+			uploadProgress := func(uploaded, total int64) {
+				if total <= 0 {
 					return
 				}
+				percent := uploaded * 100 / total
+				if percent == lastPercent {
+					return
+				}
+				lastPercent = percent
 				fyne.Do(func() {
-					progressBar.SetValue(float64(30) / 100.0)
-				})
-				transcript = translate.Translate(ctx, translate.Client, selectedFilePath, config.S3Bucket, language)
-				fyne.Do(func() {
-					progressBar.SetValue(float64(50) / 100.0)
+					progressBar.SetValue(0.30 + float64(percent)/1000.0)
 				})
-
 			}
-			promptData, err := configuration.LoadPromptContent(action)
+			transcript, err = translate.Translate(ctx, translate.Client, translate.S3Client, job, storageForJob(config, job), uploadProgress)
 			if err != nil {
-				log.Fatalf("Error loading prompt: %v", err)
+				fmt.Printf("Error transcribing job %s: %v\n", job.ID, err)
+				fyne.Do(func() {
+					progressBar.SetValue(0.0)
+					startButton.Enable()
+					dialog.ShowError(fmt.Errorf("transcription of %s failed: %v", filepath.Base(job.InputFile), err), w)
+				})
+				return
 			}
-			fullPrompt := string(promptData) + "\n" + transcript
+		}
+		fyne.Do(func() {
+			progressBar.SetValue(float64(50) / 100.0)
+		})
 
-			bedrockResult, err := llm.CallBedrock(fullPrompt, config.Model, config.AWSProfile)
-			if err != nil {
-				log.Fatalf("Error calling Bedrock: %v", err)
-			}
+		prompt, err := configuration.LoadPrompt(job.Action)
+		if err != nil {
+			// The job keeps its transcript and can be resumed after fixing the prompt
+			fmt.Printf("Error loading prompt: %v\n", err)
+			fyne.Do(func() {
+				progressBar.SetValue(0.0)
+				startButton.Enable()
+			})
+			return
+		}
+		var fullPrompt string
+		data := promptData(job, transcript)
+		data.Inputs, err = prompt.InputValues(job.Inputs)
+		if err == nil {
+			fullPrompt, err = prompt.Render(data)
+		}
+		if err != nil {
+			fmt.Printf("Error rendering prompt of %s: %v\n", job.Action, err)
+			fyne.Do(func() {
+				progressBar.SetValue(0.0)
+				startButton.Enable()
+			})
+			return
+		}
 
-			err = os.WriteFile(config.OutputPath, []byte(bedrockResult), 0644)
-			if err != nil {
-				log.Fatalf("Error writing result to %s: %v", config.OutputPath, err)
-			}
-			fmt.Printf("Done. Result written to %s\n", config.OutputPath)
+		system, err := prompt.System()
+		if err != nil {
+			// Without the global context the system prompt of the action still applies
+			fmt.Printf("Warning: %v\n", err)
+			system = strings.TrimSpace(prompt.Settings.System)
+		}
+		model := config.PromptModel(prompt)
+		params := config.PromptInference(prompt).Params()
+		bedrockResult, err := llm.CallBedrock(system, fullPrompt, model, config.AWSProfile, config.BedrockRegion, params)
+		if err != nil {
+			// The job keeps its transcript and can be resumed
+			fmt.Printf("Error calling Bedrock: %v\n", err)
+			fyne.Do(func() {
+				progressBar.SetValue(0.0)
+				startButton.Enable()
+			})
+			return
+		}
 
-			// Load result into the result tab
-			resultContent, err := os.ReadFile(config.OutputPath)
+		outputPath, err := config.PromptOutputPath(prompt, job.InputFile, time.Now())
+		if err != nil {
+			// The job keeps its transcript and can be resumed after fixing the prompt
+			fmt.Printf("Error: %v\n", err)
+			fyne.Do(func() {
+				progressBar.SetValue(0.0)
+				startButton.Enable()
+				dialog.ShowError(err, w)
+			})
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			fmt.Printf("Error creating output directory: %v\n", err)
+		}
+		err = os.WriteFile(outputPath, []byte(bedrockResult), 0644)
+		if err != nil {
+			// The output path comes from the front matter of the prompt
+			fmt.Printf("Error writing result to %s: %v\n", outputPath, err)
+			fyne.Do(func() {
+				progressBar.SetValue(0.0)
+				startButton.Enable()
+			})
+			return
+		}
+		fmt.Printf("Done. Result written to %s\n", outputPath)
+		job.SetStage(jobs.StageCompleted)
+
+		// Apply the retention setting to the uploaded audio and the transcript
+		// A resumed job or a local transcript has no S3 client yet
+		if config.S3Retention == translate.RetentionDelete {
+			var err error
+			if translate.S3Client == nil {
+				_, err = translate.InitClient(ctx, config.AWSProfile, job.Bucket, config.BucketRegion(job.Bucket))
+			}
+			if err == nil {
+				err = translate.DeleteJobObjects(ctx, translate.S3Client, job, storageForJob(config, job))
+			}
 			if err != nil {
-				fmt.Printf("Error reading result from %s: %v\n", config.OutputPath, err)
-				fyne.Do(func() {
-					resultField.SetText("Error loading result file")
-				})
-			} else {
-				fyne.Do(func() {
-					resultField.SetText(string(resultContent))
-					fmt.Println("Result loaded into Result tab")
-				})
+				fmt.Printf("Warning: retention not applied, could not delete S3 objects of job %s: %v\n", job.ID, err)
 			}
+		}
 
-			// Switch to the Result tab to show the result
+		// Load result into the result tab
+		resultContent, err := os.ReadFile(outputPath)
+		if err != nil {
+			fmt.Printf("Error reading result from %s: %v\n", outputPath, err)
 			fyne.Do(func() {
-				rightPanel.SelectTab(rightPanel.Items[1]) // Switch to second tab (Result)
+				resultField.SetText("Error loading result file")
 			})
-
+		} else {
 			fyne.Do(func() {
-				progressBar.SetValue(1.0)
-				fmt.Println("Process completed!")
-				startButton.Enable()
+				resultField.SetText(string(resultContent))
+				fmt.Println("Result loaded into Result tab")
 			})
-		}()
+		}
+
+		// Switch to the Result tab to show the result
+		fyne.Do(func() {
+			rightPanel.SelectTab(rightPanel.Items[1]) // Switch to second tab (Result)
+		})
+
+		fyne.Do(func() {
+			progressBar.SetValue(1.0)
+			fmt.Println("Process completed!")
+			startButton.Enable()
+		})
+	}
+
+	// Create the start button with Material Design microphone icon
+	// Using emoji + built-in icon for better compatibility
+	startButton = widget.NewButtonWithIcon("🎤 Start", theme.VolumeUpIcon(), func() {
+		action := config.LastActionType
+		language := languageSelect.Selected
+
+		if selectedFilePath == "" {
+			dialog.ShowInformation("No File Selected", "Please select an audio file first.", w)
+			return
+		}
+
+		prompt, err := configuration.LoadPrompt(action)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		// Speaker labels change the transcript, they are only requested for {{.Speakers}}
+		speakerLabels := prompt.UsesSpeakers()
+
+		// start runs the job with the values entered for the prompt inputs
+		start := func(inputs map[string]string) {
+			// Save current configuration
+			config.Save()
+
+			fmt.Printf("Starting process with Action: %s, Language: %s, File: %s\n", action, language, selectedFilePath)
+
+			//--------------------------------------------------------------
+			// Start processing
+			//--------------------------------------------------------------
+			// Hashing a large recording takes a while, it runs with the job
+			startButton.Disable()
+			inputFile, bucket, prefix := selectedFilePath, config.S3Bucket, translate.NormalizePrefix(config.S3Prefix)
+			go func() {
+				job, err := jobs.New(inputFile, bucket, prefix, language, action)
+				if err == nil && (speakerLabels || len(inputs) > 0) {
+					// The job keeps its inputs, a later start of the action does not change them
+					job.SpeakerLabels = speakerLabels
+					job.Inputs = inputs
+					err = jobs.Save(job)
+				}
+				if err != nil {
+					fyne.Do(func() {
+						dialog.ShowError(fmt.Errorf("failed to create job: %v", err), w)
+						startButton.Enable()
+					})
+					return
+				}
+				runJob(job)
+			}()
+		}
+
+		// Ask for the inputs of the prompt before the job starts, the answers are remembered
+		if len(prompt.Settings.Inputs) == 0 {
+			start(nil)
+			return
+		}
+		p.ShowPromptInputsDialog(prompt, config.PromptInputs[action], func(values map[string]string) {
+			config.SetPromptInputs(action, values)
+			start(maps.Clone(config.PromptInputs[action]))
+		})
 	})
 
 	//--------------------------------------------------------------
@@ -752,6 +1116,16 @@ Please process the following audio transcript and create a %s:
 	outputDirectoryLabel = widget.NewLabel(fmt.Sprintf("Output Directory: %s", filepath.Dir(config.OutputPath)))
 	outputDirectoryLabel.TextStyle.Italic = true
 
+	// AWS identity of the current session, updated when a profile is validated
+	identityLabel := widget.NewLabel(fmt.Sprintf("AWS Identity: not validated yet (profile %s)", config.AWSProfile))
+	identityLabel.TextStyle.Italic = true
+	identityLabel.Wrapping = fyne.TextWrapWord
+	awsutil.SessionChangedHandler = func(session *awsutil.Session) {
+		fyne.Do(func() {
+			identityLabel.SetText(fmt.Sprintf("AWS Identity: %s (account %s, profile %s)", session.ARN, session.Account, session.Profile))
+		})
+	}
+
 	progressLabel := widget.NewLabel("Progress:")
 	progressLabel.TextStyle.Bold = true
 
@@ -775,6 +1149,7 @@ Please process the following audio transcript and create a %s:
 					actionSelect,
 					refreshActionButton,
 					newActionButton,
+					manageActionsButton,
 				),
 				widget.NewSeparator(),
 				languageLabel,
@@ -788,6 +1163,7 @@ Please process the following audio transcript and create a %s:
 				outputPathSelector,
 				outputDirectoryLabel,
 				widget.NewSeparator(),
+				identityLabel,
 				// Start button moved here, under directory line
 				container.NewHBox(
 					startButton,
@@ -828,6 +1204,44 @@ Please process the following audio transcript and create a %s:
 
 	w.SetContent(paddedContent)
 
+	//--------------------------------------------------------------
+	// Guide through the setup on the first run
+	//--------------------------------------------------------------
+	if config.FirstRun {
+		p.ShowSetupWizard(config, onSetupFinished)
+	}
+
+	//--------------------------------------------------------------
+	// Sync the team prompts from S3, the last synced prompts are used until then
+	//--------------------------------------------------------------
+	p.SyncTeamPrompts(config, func(err error) {
+		if err == nil && config.TeamPromptsFromS3() {
+			refreshActionTypes()
+		}
+	})
+
+	//--------------------------------------------------------------
+	// Offer changed built-in prompts whose personal copy was edited
+	//--------------------------------------------------------------
+	p.ShowDefaultUpdatesDialog(defaultUpdates, func(action string) {
+		if action == config.LastActionType {
+			loadPromptContent(action)
+		}
+		refreshActionTypes()
+	})
+
+	//--------------------------------------------------------------
+	// Offer to resume jobs interrupted by a previous app exit
+	//--------------------------------------------------------------
+	if incomplete, err := jobs.ListIncomplete(); err != nil {
+		fmt.Printf("Error loading job store: %v\n", err)
+	} else if len(incomplete) > 0 {
+		fmt.Printf("Found %d incomplete jobs\n", len(incomplete))
+		p.ShowResumeJobsDialog(incomplete, func(job *jobs.Job) {
+			go runJob(job)
+		})
+	}
+
 	//--------------------------------------------------------------
 	// Set up window close handler and start application
 	//--------------------------------------------------------------
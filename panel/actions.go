@@ -0,0 +1,242 @@
+package panel
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/megaproaktiv/audionote-config/configuration"
+)
+
+// ShowManageActionsDialog renames, duplicates, forks, deletes, groups and orders the actions.
+// loadActions returns the current actions, onChanged is called after each change
+// with the action to select, empty keeps the selection. reload is set when the prompt
+// of the action changed, e.g. a personal copy was deleted
+func (p *Panel) ShowManageActionsDialog(config *configuration.Config, loadActions func() []string, onChanged func(selected string, reload bool)) {
+	w := *p.Window
+
+	actions := config.OrderActions(loadActions())
+	labels := config.ActionLabels(actions)
+	selected := ""
+	var lastDeleted *configuration.DeletedAction
+
+	actionList := widget.NewList(
+		func() int { return len(actions) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			label := labels[actions[id]]
+			if entry, err := configuration.FindPrompt(actions[id]); err == nil && len(entry.Overrides) > 0 {
+				label = fmt.Sprintf("%s (%s)", label, entry.Describe())
+			}
+			item.(*widget.Label).SetText(label)
+		},
+	)
+
+	var undoButton *widget.Button
+	// changed saves the configuration and shows the new order, selecting action
+	changed := func(action string, reload bool) {
+		config.Save()
+		actions = config.OrderActions(loadActions())
+		labels = config.ActionLabels(actions)
+		actionList.UnselectAll()
+		actionList.Refresh()
+		for i, a := range actions {
+			if a == action {
+				actionList.Select(i)
+				actionList.ScrollTo(i)
+			}
+		}
+		if lastDeleted != nil {
+			undoButton.SetText(fmt.Sprintf("Undo Delete of %s", lastDeleted.Action))
+			undoButton.Enable()
+		} else {
+			undoButton.SetText("Undo Delete")
+			undoButton.Disable()
+		}
+		onChanged(action, reload)
+	}
+
+	// askName asks for the name of a renamed or duplicated action
+	askName := func(title, confirm, name string, onName func(name string) error) {
+		nameEntry := widget.NewEntry()
+		nameEntry.SetText(name)
+		nameEntry.Validator = func(text string) error {
+			if text == "" {
+				return fmt.Errorf("action name cannot be empty")
+			}
+			if configuration.CleanActionName(text) != text {
+				return fmt.Errorf("only lowercase letters, digits and hyphens")
+			}
+			return nil
+		}
+		nameDialog := dialog.NewForm(title, confirm, "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Name", nameEntry)},
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				if err := onName(nameEntry.Text); err != nil {
+					dialog.ShowError(err, w)
+				}
+			},
+			w,
+		)
+		nameDialog.Resize(fyne.NewSize(400, 160))
+		nameDialog.Show()
+	}
+
+	upButton := widget.NewButtonWithIcon("Up", theme.MoveUpIcon(), func() {
+		config.MoveAction(loadActions(), selected, true)
+		changed(selected, false)
+	})
+	downButton := widget.NewButtonWithIcon("Down", theme.MoveDownIcon(), func() {
+		config.MoveAction(loadActions(), selected, false)
+		changed(selected, false)
+	})
+	renameButton := widget.NewButtonWithIcon("Rename...", theme.DocumentCreateIcon(), func() {
+		action := selected
+		askName(fmt.Sprintf("Rename %s", action), "Rename", action, func(name string) error {
+			if name == action {
+				return nil
+			}
+			if err := config.RenameAction(action, name); err != nil {
+				return err
+			}
+			changed(name, false)
+			return nil
+		})
+	})
+	duplicateButton := widget.NewButtonWithIcon("Duplicate...", theme.ContentCopyIcon(), func() {
+		action := selected
+		askName(fmt.Sprintf("Duplicate %s", action), "Duplicate", action+"-copy", func(name string) error {
+			if err := config.DuplicateAction(action, name); err != nil {
+				return err
+			}
+			changed(name, false)
+			return nil
+		})
+	})
+	forkButton := widget.NewButtonWithIcon("Fork to Personal...", theme.ContentPasteIcon(), func() {
+		action := selected
+		askName(fmt.Sprintf("Fork %s", action), "Fork", action, func(name string) error {
+			if err := config.ForkPrompt(action, name); err != nil {
+				return err
+			}
+			changed(name, name == action)
+			return nil
+		})
+	})
+	groupButton := widget.NewButtonWithIcon("Group...", theme.FolderIcon(), func() {
+		action := selected
+		groupEntry := widget.NewSelectEntry(config.Groups())
+		groupEntry.SetText(config.ActionGroups[action])
+		groupEntry.SetPlaceHolder("No group")
+		groupDialog := dialog.NewForm(fmt.Sprintf("Group of %s", action), "Save", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Group", groupEntry)},
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				config.SetActionGroup(action, groupEntry.Text)
+				changed(action, false)
+			},
+			w,
+		)
+		groupDialog.Resize(fyne.NewSize(400, 160))
+		groupDialog.Show()
+	})
+	deleteButton := widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), func() {
+		action := selected
+		if len(actions) <= 1 {
+			dialog.ShowError(fmt.Errorf("the last action cannot be deleted"), w)
+			return
+		}
+		dialog.ShowConfirm("Delete Action",
+			deleteMessage(action),
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				deleted, err := config.DeleteAction(action)
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				lastDeleted = deleted
+				if deleted.Reverted {
+					changed(action, true)
+					return
+				}
+				selected = ""
+				changed("", false)
+			},
+			w,
+		)
+	})
+	undoButton = widget.NewButtonWithIcon("Undo Delete", theme.ContentUndoIcon(), func() {
+		if lastDeleted == nil {
+			return
+		}
+		deleted := lastDeleted
+		if err := config.RestoreAction(deleted); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		lastDeleted = nil
+		changed(deleted.Action, deleted.Reverted)
+	})
+	undoButton.Disable()
+
+	actionButtons := []*widget.Button{upButton, downButton, renameButton, duplicateButton, forkButton, groupButton, deleteButton}
+	for _, button := range actionButtons {
+		button.Disable()
+	}
+	actionList.OnSelected = func(id widget.ListItemID) {
+		selected = actions[id]
+		for _, button := range actionButtons {
+			button.Enable()
+		}
+		// Only read-only prompts are forked, only personal prompts are renamed or deleted
+		if entry, err := configuration.FindPrompt(selected); err == nil {
+			if entry.Source.ReadOnly() {
+				renameButton.Disable()
+				deleteButton.Disable()
+			} else {
+				forkButton.Disable()
+			}
+		}
+	}
+	actionList.OnUnselected = func(widget.ListItemID) {
+		selected = ""
+		for _, button := range actionButtons {
+			button.Disable()
+		}
+	}
+
+	manageDialog := dialog.NewCustom(
+		"Manage Actions",
+		"Close",
+		container.NewBorder(
+			nil,
+			undoButton,
+			nil,
+			container.NewVBox(upButton, downButton, widget.NewSeparator(), renameButton, duplicateButton, forkButton, groupButton, deleteButton),
+			actionList,
+		),
+		w,
+	)
+	manageDialog.Resize(fyne.NewSize(500, 450))
+	manageDialog.Show()
+}
+
+// deleteMessage asks to delete an action, a personal copy reverts to the prompt it overrides
+func deleteMessage(action string) string {
+	entry, err := configuration.FindPrompt(action)
+	if err == nil && len(entry.Overrides) > 0 {
+		return fmt.Sprintf("Delete your personal copy of '%s'?\nThe %s prompt is used again, your copy stays in the history.", action, entry.Overrides[0])
+	}
+	return fmt.Sprintf("Delete the action '%s' and its prompt file?\nThe prompt stays in the history.", action)
+}
@@ -0,0 +1,105 @@
+package panel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	awsutil "github.com/megaproaktiv/audionote-config/aws"
+	"github.com/megaproaktiv/audionote-config/translate"
+)
+
+// bucketRegions are offered in the region selector of the bucket creation dialog
+var bucketRegions = []string{
+	"us-east-1", "us-east-2", "us-west-2",
+	"eu-central-1", "eu-west-1", "eu-west-2", "eu-west-3", "eu-north-1",
+	"ap-southeast-1", "ap-southeast-2", "ap-northeast-1", "ap-south-1",
+	"ca-central-1", "sa-east-1",
+}
+
+// createBucket creates and sets up the bucket in the region with the AWS profile
+func createBucket(storage translate.Storage, awsProfile, region string, lifecycleDays int) error {
+	ctx := context.Background()
+	session, err := awsutil.GetSession(ctx, awsProfile)
+	if err != nil {
+		return err
+	}
+	return translate.CreateBucket(ctx, session.S3(region), storage, region, session.Account, awsutil.PartitionFromARN(session.ARN), lifecycleDays)
+}
+
+// showCreateBucketDialog asks for the region of a new bucket and creates it
+// with public access blocked, default encryption and the bucket policy AWS Transcribe needs.
+// onCreated is called after the bucket is set up, e.g. to re-run the validation
+func (p *Panel) showCreateBucketDialog(storage translate.Storage, awsProfile string, lifecycleDays int, onCreated func()) {
+	w := *p.Window
+
+	regionEntry := widget.NewSelectEntry(bucketRegions)
+	regionEntry.SetPlaceHolder("Select or enter a region")
+	// The session may ask for the MFA code or the SSO login, so it is not loaded in the UI goroutine
+	go func() {
+		session, err := awsutil.GetSession(context.Background(), awsProfile)
+		if err != nil {
+			fmt.Printf("Could not determine the region of profile %s: %v\n", awsProfile, err)
+			return
+		}
+		fyne.Do(func() {
+			if regionEntry.Text == "" {
+				regionEntry.SetText(session.Config.Region)
+			}
+		})
+	}()
+
+	lifecycleCheck := widget.NewCheck(fmt.Sprintf("Add lifecycle rules: expire objects under %s and %s after %d days", storage.AudioPrefix(), storage.OutputPrefix(), lifecycleDays), nil)
+	if lifecycleDays > 0 {
+		lifecycleCheck.SetChecked(true)
+	} else {
+		lifecycleCheck.Text = "Add lifecycle rule (retention is set to keep forever)"
+		lifecycleCheck.Disable()
+	}
+
+	content := container.NewVBox(
+		widget.NewRichTextFromMarkdown(fmt.Sprintf("**Bucket:** %s\n\nThe bucket is created with public access blocked, default encryption and a bucket policy that allows AWS Transcribe to read audio and write transcripts below `%s`.", storage.Bucket, storage.Prefix)),
+		widget.NewLabel("Region:"),
+		regionEntry,
+		lifecycleCheck,
+	)
+
+	createDialog := dialog.NewCustomConfirm("Create S3 Bucket", "Create", "Cancel", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		region := strings.TrimSpace(regionEntry.Text)
+		if region == "" {
+			dialog.ShowError(fmt.Errorf("region cannot be empty"), w)
+			return
+		}
+		days := 0
+		if lifecycleCheck.Checked {
+			days = lifecycleDays
+		}
+
+		progress := dialog.NewCustomWithoutButtons("Create S3 Bucket",
+			widget.NewLabel(fmt.Sprintf("Creating bucket '%s' in %s...", storage.Bucket, region)), w)
+		progress.Show()
+		go func() {
+			err := createBucket(storage, awsProfile, region, days)
+			fyne.Do(func() {
+				progress.Hide()
+				if err != nil {
+					dialog.ShowError(err, w)
+					fmt.Printf("Error creating bucket %s: %v\n", storage.Bucket, err)
+					return
+				}
+				if onCreated != nil {
+					onCreated()
+				}
+			})
+		}()
+	}, w)
+	createDialog.Resize(fyne.NewSize(450, 300))
+	createDialog.Show()
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"fyne.io/fyne/v2"
@@ -11,33 +13,33 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	awsutil "github.com/megaproaktiv/audionote-config/aws"
 	"github.com/megaproaktiv/audionote-config/configuration"
+	"github.com/megaproaktiv/audionote-config/llm"
+	"github.com/megaproaktiv/audionote-config/translate"
 )
 
 // validateS3Bucket checks if the S3 bucket exists and returns its region
-func validateS3Bucket(bucketName, awsProfile string) (bool, string, string, error) {
+// The key prefix and encryption settings are verified with a test upload
+func validateS3Bucket(storage translate.Storage, awsProfile string) (bool, string, string, error) {
+	bucketName := storage.Bucket
 	if bucketName == "" {
 		return false, "", "Bucket name is empty", fmt.Errorf("bucket name is empty")
 	}
+	if err := storage.Validate(); err != nil {
+		return false, "", fmt.Sprintf("Invalid storage settings: %v", err), err
+	}
 
 	// Load AWS config using the common utility
 	ctx := context.Background()
-	cfg, err := awsutil.LoadAndValidateAWSConfig(ctx, awsProfile)
+	session, err := awsutil.GetSession(ctx, awsProfile)
 	if err != nil {
 		return false, "", fmt.Sprintf("Failed to load/validate AWS config: %v", err), err
 	}
-
-	// Create S3 client
-	s3Client := s3.NewFromConfig(cfg)
+	cfg := session.Config
 
 	// Check if bucket exists by trying to get its location
-	locationOutput, err := s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
-		Bucket: aws.String(bucketName),
-	})
-
+	bucketRegion, err := awsutil.BucketRegion(ctx, cfg, bucketName)
 	if err != nil {
 		if strings.Contains(err.Error(), "NoSuchBucket") {
 			return false, "", fmt.Sprintf("Bucket '%s' does not exist", bucketName), err
@@ -48,12 +50,6 @@ func validateS3Bucket(bucketName, awsProfile string) (bool, string, string, erro
 		return false, "", fmt.Sprintf("Error checking bucket '%s': %v", bucketName, err), err
 	}
 
-	// Get bucket region
-	bucketRegion := "us-east-1" // Default region for GetBucketLocation
-	if locationOutput.LocationConstraint != "" {
-		bucketRegion = string(locationOutput.LocationConstraint)
-	}
-
 	// Get current AWS config region
 	currentRegion := cfg.Region
 
@@ -63,13 +59,76 @@ func validateS3Bucket(bucketName, awsProfile string) (bool, string, string, erro
 	if regionMatch {
 		regionMessage = fmt.Sprintf("✓ Bucket region (%s) matches current AWS region", bucketRegion)
 	} else {
-		regionMessage = fmt.Sprintf("⚠ Bucket region (%s) differs from current AWS region (%s)", bucketRegion, currentRegion)
+		regionMessage = fmt.Sprintf("ℹ Bucket region (%s) differs from current AWS region (%s), AWS Transcribe will run in %s", bucketRegion, currentRegion, bucketRegion)
 	}
 
-	successMessage := fmt.Sprintf("✓ Bucket '%s' exists and is accessible\n%s", bucketName, regionMessage)
+	// Check write access below the prefix with the configured encryption
+	regionClient := session.S3(bucketRegion)
+	encryption := "no server-side encryption"
+	if storage.SSE != translate.SSENone {
+		encryption = storage.SSE
+	}
+	if err := translate.CheckWriteAccess(ctx, regionClient, storage); err != nil {
+		message := fmt.Sprintf("✓ Bucket '%s' exists\n%s\n✗ Test upload to prefix '%s' with %s failed: %v",
+			bucketName, regionMessage, storage.Prefix, encryption, err)
+		return false, bucketRegion, message, err
+	}
+	writeMessage := fmt.Sprintf("✓ Test upload to prefix '%s' with %s succeeded", storage.Prefix, encryption)
+
+	successMessage := fmt.Sprintf("✓ Bucket '%s' exists and is accessible\n%s\n%s", bucketName, regionMessage, writeMessage)
 	return true, bucketRegion, successMessage, nil
 }
 
+// retentionLabels and retentionValues map the retention selector to config values
+var retentionLabels = []string{"Delete after successful run", "Keep for a number of days", "Keep forever"}
+var retentionValues = []string{translate.RetentionDelete, translate.RetentionDays, translate.RetentionForever}
+
+// sseLabels and sseValues map the encryption selector to config values
+var sseLabels = []string{"None", "SSE-S3 (AES256)", "SSE-KMS"}
+var sseValues = []string{translate.SSENone, translate.SSES3, translate.SSEKMS}
+
+// installLifecycleRule installs the S3 lifecycle rule matching the retention setting on the bucket
+func installLifecycleRule(storage translate.Storage, awsProfile string, days int) error {
+	bucketName := storage.Bucket
+	if bucketName == "" {
+		return fmt.Errorf("bucket name is empty")
+	}
+
+	ctx := context.Background()
+	session, err := awsutil.GetSession(ctx, awsProfile)
+	if err != nil {
+		return err
+	}
+	bucketRegion, err := awsutil.BucketRegion(ctx, session.Config, bucketName)
+	if err != nil {
+		return err
+	}
+	return translate.ApplyLifecycleRule(ctx, session.S3(bucketRegion), storage, days)
+}
+
+// AWSEndpoints returns the configured endpoint overrides for the AWS clients
+func AWSEndpoints(config *configuration.Config) awsutil.Endpoints {
+	return awsutil.Endpoints{
+		S3:             config.EndpointS3,
+		Transcribe:     config.EndpointTranscribe,
+		STS:            config.EndpointSTS,
+		Bedrock:        config.EndpointBedrock,
+		BedrockControl: config.EndpointBedrockControl,
+		SSO:            config.EndpointSSO,
+		SSOOIDC:        config.EndpointSSOOIDC,
+		S3PathStyle:    config.S3PathStyle,
+	}
+}
+
+// AWSNetwork returns the configured proxy and CA bundle settings for all HTTP connections
+func AWSNetwork(config *configuration.Config) awsutil.Network {
+	return awsutil.Network{
+		ProxyURL: config.ProxyURL,
+		NoProxy:  config.NoProxy,
+		CABundle: config.CABundle,
+	}
+}
+
 // showConfigDialog displays the configuration dialog
 
 func (p *Panel) ShowConfigDialog(config *configuration.Config) {
@@ -82,18 +141,98 @@ func (p *Panel) ShowConfigDialog(config *configuration.Config) {
 	s3BucketEntry.SetText(config.S3Bucket)
 	s3BucketEntry.SetPlaceHolder("Enter S3 bucket name (e.g., my-audio-bucket)")
 
-	awsProfileEntry := widget.NewEntry()
-	awsProfileEntry.SetText(config.AWSProfile)
-	awsProfileEntry.SetPlaceHolder("Enter AWS profile name (e.g., default)")
+	// Create key prefix and encryption widgets
+	s3PrefixEntry := widget.NewEntry()
+	s3PrefixEntry.SetText(config.S3Prefix)
+	s3PrefixEntry.SetPlaceHolder("Enter key prefix (e.g., users/jane/summary/)")
+
+	kmsKeyEntry := widget.NewEntry()
+	kmsKeyEntry.SetText(config.S3KMSKeyID)
+	kmsKeyEntry.SetPlaceHolder("Enter KMS key ID or ARN")
+
+	sseSelect := widget.NewSelect(sseLabels, func(label string) {
+		if label == sseLabels[2] {
+			kmsKeyEntry.Enable()
+		} else {
+			kmsKeyEntry.Disable()
+		}
+	})
+	sseSelect.SetSelectedIndex(max(slices.Index(sseValues, config.S3SSE), 0))
+
+	// storageFromEntries returns the storage settings currently entered in the dialog
+	storageFromEntries := func() translate.Storage {
+		kmsKeyID := ""
+		sse := sseValues[max(sseSelect.SelectedIndex(), 0)]
+		if sse == translate.SSEKMS {
+			kmsKeyID = kmsKeyEntry.Text
+		}
+		return translate.NewStorage(s3BucketEntry.Text, s3PrefixEntry.Text, sse, kmsKeyID)
+	}
+
+	// Create AWS profile selector, SSO profiles with an expired token start a login
+	awsProfileSelect, selectedProfile := newProfileSelect(config.AWSProfile, func(name string) {
+		go p.EnsureSSOLogin(name)
+	})
+
+	// Create retention selector and days entry
+	retentionDaysEntry := widget.NewEntry()
+	retentionDaysEntry.SetText(strconv.Itoa(config.S3RetentionDays))
+	retentionDaysEntry.SetPlaceHolder("Days")
+
+	retentionSelect := widget.NewSelect(retentionLabels, func(label string) {
+		if label == retentionLabels[1] {
+			retentionDaysEntry.Enable()
+		} else {
+			retentionDaysEntry.Disable()
+		}
+	})
+	retentionSelect.SetSelectedIndex(max(slices.Index(retentionValues, config.S3Retention), 0))
+
+	// lifecycleDaysFromEntries returns the lifecycle expiration matching the entered retention
+	lifecycleDaysFromEntries := func() int {
+		retentionDays, _ := strconv.Atoi(strings.TrimSpace(retentionDaysEntry.Text))
+		return translate.LifecycleDays(retentionValues[max(retentionSelect.SelectedIndex(), 0)], retentionDays)
+	}
+
+	// Create lifecycle button to install a matching rule on the bucket
+	lifecycleButton := widget.NewButtonWithIcon("Install Lifecycle Rule", theme.UploadIcon(), nil)
+	lifecycleButton.OnTapped = func() {
+		storage := storageFromEntries()
+		awsProfile := selectedProfile()
+		days := lifecycleDaysFromEntries()
+		rule := translate.DescribeLifecycleRule(storage, days)
+
+		// The rule deletes objects in a bucket that may be shared, so it is shown before it is applied
+		dialog.ShowConfirm("Install Lifecycle Rule", rule+"\n\nApply it to the bucket?", func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			lifecycleButton.Disable()
+			go func() {
+				err := installLifecycleRule(storage, awsProfile, days)
+				fyne.Do(func() {
+					lifecycleButton.Enable()
+					if err != nil {
+						dialog.ShowError(fmt.Errorf("failed to install lifecycle rule: %v", err), *w)
+						return
+					}
+					dialog.ShowInformation("Lifecycle Rule", "Applied. "+rule, *w)
+				})
+			}()
+		}, *w)
+	}
+	retentionContainer := container.NewBorder(nil, nil, nil,
+		container.NewHBox(retentionDaysEntry, lifecycleButton), retentionSelect)
+
+	// Region found by the last successful bucket check, cached on save
+	var checkedBucket, checkedRegion string
 
 	// Create S3 bucket check button
 	s3CheckButton := widget.NewButtonWithIcon("Check", theme.ConfirmIcon(), nil)
 	s3CheckButton.OnTapped = func() {
-		bucketName := strings.TrimSpace(s3BucketEntry.Text)
-		awsProfile := strings.TrimSpace(awsProfileEntry.Text)
-		if awsProfile == "" {
-			awsProfile = "default"
-		}
+		storage := storageFromEntries()
+		bucketName := storage.Bucket
+		awsProfile := selectedProfile()
 
 		// Change button text to indicate checking
 		s3CheckButton.SetText("Checking...")
@@ -101,55 +240,168 @@ func (p *Panel) ShowConfigDialog(config *configuration.Config) {
 
 		// Perform validation in a goroutine to avoid blocking UI
 		go func() {
-			exists, region, message, err := validateS3Bucket(bucketName, awsProfile)
-
-			// Update UI on main thread
-			s3CheckButton.SetText("Check")
-			s3CheckButton.Enable()
-
-			var dialogTitle string
-			var dialogIcon fyne.Resource
-
-			if exists {
-				dialogTitle = "S3 Bucket Validation - Success"
-				dialogIcon = theme.ConfirmIcon()
-			} else {
-				dialogTitle = "S3 Bucket Validation - Error"
-				dialogIcon = theme.ErrorIcon()
-			}
+			exists, region, message, err := validateS3Bucket(storage, awsProfile)
+
+			// Update UI on main thread, the bucket check result is only read there
+			fyne.Do(func() {
+				s3CheckButton.SetText("Check")
+				s3CheckButton.Enable()
+
+				var dialogTitle string
+				var dialogIcon fyne.Resource
+
+				if exists {
+					dialogTitle = "S3 Bucket Validation - Success"
+					dialogIcon = theme.ConfirmIcon()
+				} else {
+					dialogTitle = "S3 Bucket Validation - Error"
+					dialogIcon = theme.ErrorIcon()
+				}
 
-			// Show result dialog
-			resultDialog := dialog.NewCustom(
-				dialogTitle,
-				"OK",
-				container.NewVBox(
+				resultContent := container.NewVBox(
 					container.NewHBox(
 						widget.NewIcon(dialogIcon),
 						widget.NewLabel(dialogTitle),
 					),
 					widget.NewSeparator(),
 					widget.NewRichTextFromMarkdown(fmt.Sprintf("**Bucket:** %s\n**AWS Profile:** %s\n\n%s", bucketName, awsProfile, message)),
-				),
-				*w,
-			)
-			resultDialog.Resize(fyne.NewSize(400, 200))
-			resultDialog.Show()
-
-			if err != nil {
-				fmt.Printf("S3 bucket validation error: %v\n", err)
-			} else {
-				fmt.Printf("S3 bucket validation success: %s in region %s\n", bucketName, region)
-			}
+				)
+
+				// Show result dialog, offer to create a missing bucket
+				var resultDialog dialog.Dialog
+				if err != nil && strings.Contains(err.Error(), "NoSuchBucket") {
+					resultDialog = dialog.NewCustomConfirm(dialogTitle, "Create Bucket", "Close", resultContent, func(create bool) {
+						if create {
+							p.showCreateBucketDialog(storage, awsProfile, lifecycleDaysFromEntries(), s3CheckButton.OnTapped)
+						}
+					}, *w)
+				} else {
+					resultDialog = dialog.NewCustom(dialogTitle, "OK", resultContent, *w)
+				}
+				resultDialog.Resize(fyne.NewSize(400, 200))
+				resultDialog.Show()
+
+				if err != nil {
+					fmt.Printf("S3 bucket validation error: %v\n", err)
+				} else {
+					fmt.Printf("S3 bucket validation success: %s in region %s\n", bucketName, region)
+					checkedBucket, checkedRegion = bucketName, region
+				}
+			})
 		}()
 	}
 
 	// Create S3 bucket container with entry and check button
 	s3BucketContainer := container.NewBorder(nil, nil, nil, s3CheckButton, s3BucketEntry)
 
-	// Create model entry
-	modelEntry := widget.NewEntry()
-	modelEntry.SetText(config.Model)
-	modelEntry.SetPlaceHolder("Enter Bedrock model ID (e.g., anthropic.claude-3-5-sonnet-20240620-v1:0)")
+	// Create Bedrock region entry
+	bedrockRegionEntry := widget.NewEntry()
+	bedrockRegionEntry.SetText(config.BedrockRegion)
+	bedrockRegionEntry.SetPlaceHolder("Region of the AWS profile (e.g., eu-central-1)")
+
+	// Create model picker, it lists the models of the Bedrock region
+	modelPicker, selectedModel := newModelPicker(config.Model, selectedProfile, func() string {
+		return strings.TrimSpace(bedrockRegionEntry.Text)
+	})
+
+	// Create inference parameter entries, actions can override them in the prompt editor
+	inferenceForm, inferenceValues := newInferenceForm(config.Inference, configuration.Inference{})
+	inferenceAccordion := widget.NewAccordion(widget.NewAccordionItem("Inference Parameters", inferenceForm))
+	if !config.Inference.IsZero() {
+		inferenceAccordion.Open(0)
+	}
+
+	// Create endpoint override entries, e.g. for VPC interface endpoints or LocalStack
+	newEndpointEntry := func(value, placeHolder string) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetText(value)
+		entry.SetPlaceHolder(placeHolder)
+		return entry
+	}
+	endpointS3Entry := newEndpointEntry(config.EndpointS3, "e.g. https://bucket.vpce-0123-abcd.s3.eu-central-1.vpce.amazonaws.com")
+	endpointTranscribeEntry := newEndpointEntry(config.EndpointTranscribe, "e.g. http://localhost:4566")
+	endpointSTSEntry := newEndpointEntry(config.EndpointSTS, "e.g. https://sts.eu-central-1.amazonaws.com")
+	endpointBedrockEntry := newEndpointEntry(config.EndpointBedrock, "e.g. https://vpce-0123-abcd.bedrock-runtime.eu-central-1.vpce.amazonaws.com")
+	endpointBedrockControlEntry := newEndpointEntry(config.EndpointBedrockControl, "e.g. https://vpce-0123-abcd.bedrock.eu-central-1.vpce.amazonaws.com")
+	endpointSSOEntry := newEndpointEntry(config.EndpointSSO, "e.g. https://portal.sso.eu-central-1.amazonaws.com")
+	endpointSSOOIDCEntry := newEndpointEntry(config.EndpointSSOOIDC, "e.g. https://oidc.eu-central-1.amazonaws.com")
+	s3PathStyleCheck := widget.NewCheck("S3 path-style addressing (required by LocalStack and most S3 stand-ins)", nil)
+	s3PathStyleCheck.SetChecked(config.S3PathStyle)
+
+	endpointsForm := widget.NewForm(
+		widget.NewFormItem("S3", endpointS3Entry),
+		widget.NewFormItem("Transcribe", endpointTranscribeEntry),
+		widget.NewFormItem("STS", endpointSTSEntry),
+		widget.NewFormItem("Bedrock", endpointBedrockEntry),
+		widget.NewFormItem("Bedrock Control", endpointBedrockControlEntry),
+		widget.NewFormItem("SSO", endpointSSOEntry),
+		widget.NewFormItem("SSO OIDC", endpointSSOOIDCEntry),
+	)
+	endpointsAccordion := widget.NewAccordion(widget.NewAccordionItem("Service Endpoints",
+		container.NewVBox(endpointsForm, s3PathStyleCheck)))
+	if AWSEndpoints(config) != (awsutil.Endpoints{}) {
+		endpointsAccordion.Open(0)
+	}
+
+	// Create network entries for a corporate proxy and its CA certificate
+	proxyEntry := widget.NewEntry()
+	proxyEntry.SetText(config.ProxyURL)
+	proxyEntry.SetPlaceHolder("e.g. http://proxy.example.com:3128, empty uses HTTPS_PROXY")
+	noProxyEntry := widget.NewEntry()
+	noProxyEntry.SetText(config.NoProxy)
+	noProxyEntry.SetPlaceHolder("e.g. localhost,.internal.example.com,10.0.0.0/8")
+	caBundleEntry := widget.NewEntry()
+	caBundleEntry.SetText(config.CABundle)
+	caBundleEntry.SetPlaceHolder("PEM file with additional trusted certificates")
+	caBundleButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+			caBundleEntry.SetText(reader.URI().Path())
+		}, *w)
+		fileDialog.Show()
+	})
+
+	networkForm := widget.NewForm(
+		widget.NewFormItem("Proxy", proxyEntry),
+		widget.NewFormItem("No Proxy", noProxyEntry),
+		widget.NewFormItem("CA Bundle", container.NewBorder(nil, nil, nil, caBundleButton, caBundleEntry)),
+	)
+	networkAccordion := widget.NewAccordion(widget.NewAccordionItem("Proxy and Certificates", networkForm))
+	if config.ProxyURL != "" || config.NoProxy != "" || config.CABundle != "" {
+		networkAccordion.Open(0)
+	}
+
+	// Create team prompt source entries, a shared directory or a prefix in the bucket
+	teamPromptDirEntry := widget.NewEntry()
+	teamPromptDirEntry.SetText(config.TeamPromptDir)
+	teamPromptDirEntry.SetPlaceHolder("e.g. /Volumes/team/audionote-prompts, empty for none")
+	teamPromptDirButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		folderDialog := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			teamPromptDirEntry.SetText(uri.Path())
+		}, *w)
+		folderDialog.Show()
+	})
+	teamPromptS3PrefixEntry := widget.NewEntry()
+	teamPromptS3PrefixEntry.SetText(config.TeamPromptS3Prefix)
+	teamPromptS3PrefixEntry.SetPlaceHolder("e.g. team/prompts/")
+	teamPromptDirRow := container.NewBorder(nil, nil, nil, teamPromptDirButton, teamPromptDirEntry)
+	teamSourceSelect := widget.NewSelect(teamSourceLabels, func(label string) {
+		teamPromptDirRow.Hide()
+		teamPromptS3PrefixEntry.Hide()
+		switch label {
+		case teamSourceLabels[1]:
+			teamPromptDirRow.Show()
+		case teamSourceLabels[2]:
+			teamPromptS3PrefixEntry.Show()
+		}
+	})
+	teamSourceSelect.SetSelectedIndex(max(slices.Index(teamSourceValues, config.TeamPromptSource), 0))
 
 	// Create output path entry
 	outputPathEntry := widget.NewEntry()
@@ -168,8 +420,16 @@ func (p *Panel) ShowConfigDialog(config *configuration.Config) {
 
 	// Create labels with descriptions
 	s3Label := widget.NewRichTextFromMarkdown("**S3 Bucket:**\nThe AWS S3 bucket where audio files will be stored or retrieved.")
-	awsLabel := widget.NewRichTextFromMarkdown("**AWS Profile:**\nThe AWS CLI profile to use for authentication.")
-	modelLabel := widget.NewRichTextFromMarkdown("**Bedrock Model:**\nThe AWS Bedrock model ID to use for processing (e.g., anthropic.claude-3-5-sonnet-20240620-v1:0).")
+	prefixLabel := widget.NewRichTextFromMarkdown("**S3 Key Prefix:**\nUploads go below this prefix, AWS Transcribe writes to its output/ sub-prefix.")
+	sseLabel := widget.NewRichTextFromMarkdown("**Server-Side Encryption:**\nEncryption for uploads and AWS Transcribe output. SSE-KMS requires a KMS key ID.")
+	retentionLabel := widget.NewRichTextFromMarkdown("**S3 Retention:**\nHow long uploaded audio and transcripts are kept in the bucket.")
+	awsLabel := widget.NewRichTextFromMarkdown("**AWS Profile:**\nThe profile from ~/.aws/config or ~/.aws/credentials, with its type and region.")
+	modelLabel := widget.NewRichTextFromMarkdown("**Bedrock Model:**\nThe AWS Bedrock model ID or inference profile to use for processing. Load Models lists the models of the Bedrock region, type to search, Test invokes the model.")
+	bedrockRegionLabel := widget.NewRichTextFromMarkdown("**Bedrock Region:**\nThe AWS region for Bedrock calls. Leave empty to use the region of the AWS profile.")
+	inferenceLabel := widget.NewRichTextFromMarkdown("**Inference:**\nTemperature, top-p, max tokens and stop sequences for all actions. Empty uses the model default. Parameters... in the prompt editor overrides them per action.")
+	endpointsLabel := widget.NewRichTextFromMarkdown("**Endpoints:**\nOverride the service endpoints, e.g. with VPC interface endpoints or a local stand-in. Empty uses the AWS endpoint of the region. Applied after saving.")
+	networkLabel := widget.NewRichTextFromMarkdown("**Network:**\nHTTP(S) proxy, hosts reached without proxy and a CA bundle for TLS-intercepting proxies. Applied after saving.")
+	teamPromptDirLabel := widget.NewRichTextFromMarkdown("**Team Prompts:**\nShared prompt-<action>.txt files in a directory or below a prefix in the S3 bucket, synced on start and with the refresh button. They are read-only and override the built-in prompts, personal prompts override them.")
 	outputPathLabel := widget.NewRichTextFromMarkdown("**Output File Path:**\nThe path where the processing result will be saved.")
 	outputLabel := widget.NewRichTextFromMarkdown("**Output Display Lines:**\nMinimum number of lines to display in the output area (5-50).")
 
@@ -178,11 +438,38 @@ func (p *Panel) ShowConfigDialog(config *configuration.Config) {
 		s3Label,
 		s3BucketContainer,
 		widget.NewSeparator(),
+		prefixLabel,
+		s3PrefixEntry,
+		widget.NewSeparator(),
+		sseLabel,
+		sseSelect,
+		kmsKeyEntry,
+		widget.NewSeparator(),
+		retentionLabel,
+		retentionContainer,
+		widget.NewSeparator(),
 		awsLabel,
-		awsProfileEntry,
+		awsProfileSelect,
+		widget.NewSeparator(),
+		bedrockRegionLabel,
+		bedrockRegionEntry,
 		widget.NewSeparator(),
 		modelLabel,
-		modelEntry,
+		modelPicker,
+		widget.NewSeparator(),
+		inferenceLabel,
+		inferenceAccordion,
+		widget.NewSeparator(),
+		endpointsLabel,
+		endpointsAccordion,
+		widget.NewSeparator(),
+		networkLabel,
+		networkAccordion,
+		widget.NewSeparator(),
+		teamPromptDirLabel,
+		teamSourceSelect,
+		teamPromptDirRow,
+		teamPromptS3PrefixEntry,
 		widget.NewSeparator(),
 		outputPathLabel,
 		outputPathEntry,
@@ -199,22 +486,23 @@ func (p *Panel) ShowConfigDialog(config *configuration.Config) {
 		"Configuration Settings",
 		"Save",
 		"Cancel",
-		formContent,
+		container.NewVScroll(formContent),
 		func(confirmed bool) {
 			if confirmed {
 				// Basic validation
-				s3Bucket := s3BucketEntry.Text
-				awsProfile := awsProfileEntry.Text
-				model := modelEntry.Text
+				s3Bucket := strings.TrimSpace(s3BucketEntry.Text)
+				awsProfile := selectedProfile()
+				model := selectedModel()
 				outputPath := outputPathEntry.Text
 				outputLines := int(outputLinesSlider.Value)
-
-				if awsProfile == "" {
-					awsProfile = "default"
+				retention := retentionValues[max(retentionSelect.SelectedIndex(), 0)]
+				retentionDays, err := strconv.Atoi(strings.TrimSpace(retentionDaysEntry.Text))
+				if err != nil || retentionDays < 1 {
+					retentionDays = config.S3RetentionDays
 				}
 
 				if model == "" {
-					model = "anthropic.claude-3-5-sonnet-20240620-v1:0"
+					model = llm.DefaultModel
 				}
 
 				// Validate output path
@@ -230,14 +518,110 @@ func (p *Panel) ShowConfigDialog(config *configuration.Config) {
 				}
 
 				// Update configuration
+				storage := storageFromEntries()
+				if err := storage.Validate(); err != nil {
+					dialog.ShowError(fmt.Errorf("invalid storage settings: %v", err), *w)
+					return
+				}
+
+				inference, err := inferenceValues()
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("invalid inference parameters: %v", err), *w)
+					return
+				}
+
+				endpoints := awsutil.Endpoints{
+					S3:             strings.TrimSpace(endpointS3Entry.Text),
+					Transcribe:     strings.TrimSpace(endpointTranscribeEntry.Text),
+					STS:            strings.TrimSpace(endpointSTSEntry.Text),
+					Bedrock:        strings.TrimSpace(endpointBedrockEntry.Text),
+					BedrockControl: strings.TrimSpace(endpointBedrockControlEntry.Text),
+					SSO:            strings.TrimSpace(endpointSSOEntry.Text),
+					SSOOIDC:        strings.TrimSpace(endpointSSOOIDCEntry.Text),
+					S3PathStyle:    s3PathStyleCheck.Checked,
+				}
+				if err := endpoints.Validate(); err != nil {
+					dialog.ShowError(fmt.Errorf("invalid endpoint: %v", err), *w)
+					return
+				}
+
+				network := awsutil.Network{
+					ProxyURL: strings.TrimSpace(proxyEntry.Text),
+					NoProxy:  strings.TrimSpace(noProxyEntry.Text),
+					CABundle: strings.TrimSpace(caBundleEntry.Text),
+				}
+				if err := network.Validate(); err != nil {
+					dialog.ShowError(fmt.Errorf("invalid network settings: %v", err), *w)
+					return
+				}
+
+				teamSource := teamSourceValues[max(teamSourceSelect.SelectedIndex(), 0)]
+				if teamSource == configuration.TeamSourceS3 && strings.TrimSpace(teamPromptS3PrefixEntry.Text) == "" {
+					dialog.ShowError(fmt.Errorf("the S3 prefix of the team prompts is empty"), *w)
+					return
+				}
+				// The retention lifecycle rules expire the audio and transcripts of the app
+				if teamSource == configuration.TeamSourceS3 {
+					teamPrefix := translate.NormalizePrefix(teamPromptS3PrefixEntry.Text)
+					for _, expiring := range storage.LifecyclePrefixes() {
+						if strings.HasPrefix(teamPrefix, expiring) {
+							dialog.ShowError(fmt.Errorf("the team prompts cannot be under %s, its objects expire with the retention rule", expiring), *w)
+							return
+						}
+					}
+				}
+				teamPromptsChanged := teamSource != config.TeamPromptSource ||
+					strings.TrimSpace(teamPromptDirEntry.Text) != config.TeamPromptDir ||
+					strings.TrimSpace(teamPromptS3PrefixEntry.Text) != config.TeamPromptS3Prefix ||
+					(teamSource == configuration.TeamSourceS3 && s3Bucket != config.S3Bucket)
+
+				// The cached bucket region is only valid for the checked bucket
+				if s3Bucket != config.S3Bucket {
+					config.S3BucketRegion = ""
+				}
+				if checkedBucket == s3Bucket && checkedRegion != "" {
+					config.S3BucketRegion = checkedRegion
+				}
 				config.S3Bucket = s3Bucket
+				config.S3Prefix = storage.Prefix
+				config.S3SSE = storage.SSE
+				config.S3KMSKeyID = storage.KMSKeyID
 				config.AWSProfile = awsProfile
 				config.Model = model
+				config.BedrockRegion = strings.TrimSpace(bedrockRegionEntry.Text)
+				config.Inference = inference
 				config.OutputPath = outputPath
 				config.OutputLines = outputLines
+				config.S3Retention = retention
+				config.S3RetentionDays = retentionDays
+				config.EndpointS3 = endpoints.S3
+				config.EndpointTranscribe = endpoints.Transcribe
+				config.EndpointSTS = endpoints.STS
+				config.EndpointBedrock = endpoints.Bedrock
+				config.EndpointBedrockControl = endpoints.BedrockControl
+				config.EndpointSSO = endpoints.SSO
+				config.EndpointSSOOIDC = endpoints.SSOOIDC
+				config.S3PathStyle = endpoints.S3PathStyle
+				awsutil.SetEndpoints(endpoints)
+				config.ProxyURL = network.ProxyURL
+				config.NoProxy = network.NoProxy
+				config.CABundle = network.CABundle
+				if err := awsutil.SetNetwork(network); err != nil {
+					fmt.Printf("Error applying network settings: %v\n", err)
+				}
+
+				config.SetTeamPrompts(teamSource, teamPromptDirEntry.Text, teamPromptS3PrefixEntry.Text)
 
 				// Save configuration
 				config.Save()
+				if teamPromptsChanged && p.RefreshActions != nil {
+					p.SyncTeamPrompts(config, func(err error) {
+						if err != nil {
+							dialog.ShowError(fmt.Errorf("team prompts not synced: %v", err), *w)
+						}
+						p.RefreshActions()
+					})
+				}
 
 				// Update output field size if it changed
 				if outputField != nil {
@@ -246,8 +630,8 @@ func (p *Panel) ShowConfigDialog(config *configuration.Config) {
 				}
 
 				// Show success message
-				successMsg := fmt.Sprintf("Configuration saved successfully!\n\nS3 Bucket: %s\nAWS Profile: %s\nModel: %s\nOutput Path: %s\nOutput Lines: %d",
-					s3Bucket, awsProfile, model, outputPath, outputLines)
+				successMsg := fmt.Sprintf("Configuration saved successfully!\n\nS3 Bucket: %s\nAWS Profile: %s\nModel: %s\nOutput Path: %s\nOutput Lines: %d\nRetention: %s",
+					s3Bucket, awsProfile, model, outputPath, outputLines, retention)
 				dialog.ShowInformation("Configuration Saved", successMsg, *w)
 
 				fmt.Printf("Configuration updated - S3 Bucket: %s, AWS Profile: %s, Model: %s, Output Path: %s, Output Lines: %d\n",
@@ -257,6 +641,6 @@ func (p *Panel) ShowConfigDialog(config *configuration.Config) {
 		*w,
 	)
 
-	configDialog.Resize(fyne.NewSize(500, 500))
+	configDialog.Resize(fyne.NewSize(550, 600))
 	configDialog.Show()
 }
@@ -0,0 +1,64 @@
+package panel
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/megaproaktiv/audionote-config/configuration"
+)
+
+// ShowGlobalContextDialog edits the global context, which is sent as system prompt
+// for every action, e.g. the author, the company and a style guide
+func (p *Panel) ShowGlobalContextDialog() {
+	w := *p.Window
+	content, err := configuration.LoadGlobalContext()
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	contextEntry := widget.NewMultiLineEntry()
+	contextEntry.Wrapping = fyne.TextWrapWord
+	contextEntry.SetText(content)
+	contextEntry.SetPlaceHolder("e.g.\nI am Jane Doe, solutions architect at Example Corp.\nWrite in active voice, avoid marketing language.")
+	contextEntry.SetMinRowsVisible(12)
+
+	partials, err := configuration.ListPartials()
+	if err != nil {
+		fmt.Printf("Error reading partials: %v\n", err)
+	}
+	partialsText := fmt.Sprintf("Partials (built-in and in %s): none", configuration.PartialsDir())
+	if len(partials) > 0 {
+		partialsText = fmt.Sprintf("Partials (built-in and in %s): %v", configuration.PartialsDir(), partials)
+	}
+	partialsLabel := widget.NewLabel(partialsText)
+	partialsLabel.Wrapping = fyne.TextWrapWord
+
+	contextDialog := dialog.NewCustomConfirm(
+		"Global Context",
+		"Save",
+		"Cancel",
+		container.NewBorder(
+			widget.NewLabel("Sent as system prompt before the system prompt of every action."),
+			partialsLabel,
+			nil, nil,
+			contextEntry,
+		),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := configuration.SaveGlobalContext(contextEntry.Text); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			fmt.Printf("Global context saved to %s\n", configuration.ContextFile())
+		},
+		w,
+	)
+	contextDialog.Resize(fyne.NewSize(550, 450))
+	contextDialog.Show()
+}
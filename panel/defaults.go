@@ -0,0 +1,104 @@
+package panel
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/megaproaktiv/audionote-config/configuration"
+)
+
+// ShowDefaultUpdatesDialog offers changed built-in prompts whose personal copy was edited,
+// one after the other with the diff from the personal copy. onApplied gets each action
+// that uses the built-in prompt again
+func (p *Panel) ShowDefaultUpdatesDialog(updates []configuration.DefaultUpdate, onApplied func(action string)) {
+	if len(updates) == 0 {
+		return
+	}
+	w := *p.Window
+	update := updates[0]
+	next := func() {
+		p.ShowDefaultUpdatesDialog(updates[1:], onApplied)
+	}
+
+	updateDialog := dialog.NewCustomConfirm(
+		fmt.Sprintf("New Default Prompt: %s", update.Action),
+		"Use New Default",
+		"Keep Mine",
+		container.NewBorder(
+			widget.NewLabel(fmt.Sprintf("The built-in prompt of '%s' changed (version %d), you edited your copy.\nChanges from your copy to the new default:", update.Action, update.Version)),
+			nil, nil, nil,
+			newDiffView(update.Personal, update.Default),
+		),
+		func(useDefault bool) {
+			if !useDefault {
+				if err := update.Keep(); err != nil {
+					fmt.Printf("Error recording kept prompt %s: %v\n", update.Action, err)
+				}
+				next()
+				return
+			}
+			if err := update.Apply(); err != nil {
+				dialog.ShowError(err, w)
+				next()
+				return
+			}
+			onApplied(update.Action)
+			next()
+		},
+		w,
+	)
+	updateDialog.Resize(fyne.NewSize(700, 550))
+	updateDialog.Show()
+}
+
+// ShowRestoreDefaultDialog shows the changes from the editor text to the prompt the action uses
+// without its personal copy and removes the personal copy when confirmed. onRestored is called after the restore
+func (p *Panel) ShowRestoreDefaultDialog(action string, editor string, onRestored func()) {
+	w := *p.Window
+	if _, err := configuration.BuiltInPrompt(action); err != nil {
+		dialog.ShowError(fmt.Errorf("'%s' has no default prompt to restore", action), w)
+		return
+	}
+	entry, err := configuration.FindPrompt(action)
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	if entry.Source == configuration.SourceTeam {
+		dialog.ShowError(fmt.Errorf("'%s' uses the team prompt, it overrides the built-in default and cannot be restored", action), w)
+		return
+	}
+	shared, source, err := configuration.SharedPrompt(action)
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	message := fmt.Sprintf("Replace the prompt of '%s' with its built-in default?\nYour version stays in the history.", action)
+	if source == configuration.SourceTeam {
+		message = fmt.Sprintf("Remove your copy of '%s'?\nThe team prompt is used, it overrides the built-in default. Your version stays in the history.", action)
+	}
+
+	restoreDialog := dialog.NewCustomConfirm(
+		fmt.Sprintf("Restore Default: %s", action),
+		"Restore",
+		"Cancel",
+		container.NewBorder(widget.NewLabel(message), nil, nil, nil, newDiffView(editor, shared)),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := configuration.RestoreDefaultPrompt(action); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			onRestored()
+		},
+		w,
+	)
+	restoreDialog.Resize(fyne.NewSize(700, 550))
+	restoreDialog.Show()
+}
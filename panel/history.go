@@ -0,0 +1,166 @@
+package panel
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/megaproaktiv/audionote-config/configuration"
+)
+
+// editorVersion is the option for the unsaved text in the prompt editor
+const editorVersion = "Editor (unsaved)"
+
+// ShowPromptHistoryDialog lists the saved versions of an action's prompt,
+// shows the diff of any two and restores one. onRestore gets the restored content
+func (p *Panel) ShowPromptHistoryDialog(action string, editor string, onRestore func(content string)) {
+	w := *p.Window
+	versions, err := configuration.ListPromptVersions(action)
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	if len(versions) == 0 {
+		dialog.ShowInformation("Prompt History", fmt.Sprintf("No saved versions of '%s' yet.\nEvery save adds a version.", action), w)
+		return
+	}
+
+	// Option 0 is the editor, option i is versions[i-1]
+	options := []string{editorVersion}
+	for _, version := range versions {
+		options = append(options, version.Label())
+	}
+	content := func(index int) (string, error) {
+		if index <= 0 {
+			return editor, nil
+		}
+		return versions[index-1].Content()
+	}
+
+	diffText := widget.NewRichText()
+	diffText.Wrapping = fyne.TextWrapWord
+	summary := widget.NewLabel("")
+
+	fromSelect := widget.NewSelect(options, nil)
+	toSelect := widget.NewSelect(options, nil)
+	var restoreButton *widget.Button
+
+	showDiff := func() {
+		from, to := fromSelect.SelectedIndex(), toSelect.SelectedIndex()
+		if from < 0 || to < 0 {
+			return
+		}
+		if from == 0 {
+			restoreButton.Disable()
+		} else {
+			restoreButton.Enable()
+		}
+		fromContent, err := content(from)
+		if err != nil {
+			summary.SetText(err.Error())
+			return
+		}
+		toContent, err := content(to)
+		if err != nil {
+			summary.SetText(err.Error())
+			return
+		}
+
+		segments, added, removed := diffSegments(fromContent, toContent)
+		diffText.Segments = segments
+		diffText.Refresh()
+		summary.SetText(diffSummary(added, removed))
+	}
+	fromSelect.OnChanged = func(string) { showDiff() }
+	toSelect.OnChanged = func(string) { showDiff() }
+
+	var historyDialog dialog.Dialog
+	restoreButton = widget.NewButtonWithIcon("Restore Version", theme.HistoryIcon(), func() {
+		index := fromSelect.SelectedIndex()
+		if index <= 0 {
+			return
+		}
+		version := versions[index-1]
+		dialog.ShowConfirm(
+			"Restore Version",
+			fmt.Sprintf("Replace the prompt '%s' with the version of %s?\nThe current prompt stays in the history.", action, version.Label()),
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				if err := configuration.RestorePromptVersion(version); err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				restored, err := version.Content()
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				historyDialog.Hide()
+				onRestore(restored)
+			},
+			w,
+		)
+	})
+
+	// Start with the latest saved version against the editor
+	fromSelect.SetSelectedIndex(1)
+	toSelect.SetSelectedIndex(0)
+
+	historyDialog = dialog.NewCustom(
+		fmt.Sprintf("Prompt History: %s", action),
+		"Close",
+		container.NewBorder(
+			widget.NewForm(
+				widget.NewFormItem("Compare", fromSelect),
+				widget.NewFormItem("With", toSelect),
+			),
+			container.NewBorder(nil, nil, nil, restoreButton, summary),
+			nil, nil,
+			container.NewScroll(diffText),
+		),
+		w,
+	)
+	historyDialog.Resize(fyne.NewSize(700, 550))
+	historyDialog.Show()
+}
+
+// diffSegments renders the line diff from a to b, added lines green and removed lines red
+func diffSegments(a, b string) (segments []widget.RichTextSegment, added, removed int) {
+	for _, line := range configuration.DiffLines(a, b) {
+		style := widget.RichTextStyle{TextStyle: fyne.TextStyle{Monospace: true}}
+		switch line.Op {
+		case configuration.DiffInsert:
+			style.ColorName = theme.ColorNameSuccess
+			added++
+		case configuration.DiffDelete:
+			style.ColorName = theme.ColorNameError
+			removed++
+		}
+		segments = append(segments, &widget.TextSegment{
+			Style: style,
+			Text:  fmt.Sprintf("%c %s", line.Op, line.Text),
+		})
+	}
+	return segments, added, removed
+}
+
+// diffSummary counts the changed lines of a diff
+func diffSummary(added, removed int) string {
+	if added == 0 && removed == 0 {
+		return "The versions are identical"
+	}
+	return fmt.Sprintf("%d lines added, %d lines removed", added, removed)
+}
+
+// newDiffView shows the line diff from a to b with a summary below
+func newDiffView(a, b string) fyne.CanvasObject {
+	segments, added, removed := diffSegments(a, b)
+	diffText := widget.NewRichText(segments...)
+	diffText.Wrapping = fyne.TextWrapWord
+	return container.NewBorder(nil, widget.NewLabel(diffSummary(added, removed)), nil, nil, container.NewScroll(diffText))
+}
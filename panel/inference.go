@@ -0,0 +1,137 @@
+package panel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/megaproaktiv/audionote-config/configuration"
+	"github.com/megaproaktiv/audionote-config/llm"
+)
+
+// newInferenceForm creates entries for the inference parameters. Empty entries are not set,
+// their placeholder shows the value used instead from fallback.
+// The returned function parses and validates the entered parameters
+func newInferenceForm(values configuration.Inference, fallback configuration.Inference) (*widget.Form, func() (configuration.Inference, error)) {
+	formatFloat := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', -1, 64)
+	}
+	placeHolder := func(value string) string {
+		if value == "" {
+			return "model default"
+		}
+		return value
+	}
+
+	temperatureEntry := widget.NewEntry()
+	temperatureEntry.SetText(formatFloat(values.Temperature))
+	temperatureEntry.SetPlaceHolder(placeHolder(formatFloat(fallback.Temperature)))
+
+	topPEntry := widget.NewEntry()
+	topPEntry.SetText(formatFloat(values.TopP))
+	topPEntry.SetPlaceHolder(placeHolder(formatFloat(fallback.TopP)))
+
+	maxTokensEntry := widget.NewEntry()
+	if values.MaxTokens != nil {
+		maxTokensEntry.SetText(strconv.Itoa(*values.MaxTokens))
+	}
+	fallbackMaxTokens := ""
+	if fallback.MaxTokens != nil {
+		fallbackMaxTokens = strconv.Itoa(*fallback.MaxTokens)
+	}
+	maxTokensEntry.SetPlaceHolder(placeHolder(fallbackMaxTokens))
+
+	stopEntry := widget.NewMultiLineEntry()
+	stopEntry.SetText(strings.Join(values.StopSequences, "\n"))
+	stopEntry.SetPlaceHolder(placeHolder(strings.Join(fallback.StopSequences, ", ")) + "\nOne sequence per line")
+	stopEntry.SetMinRowsVisible(2)
+
+	form := widget.NewForm(
+		widget.NewFormItem("Temperature", temperatureEntry),
+		widget.NewFormItem("Top-P", topPEntry),
+		widget.NewFormItem("Max Tokens", maxTokensEntry),
+		widget.NewFormItem("Stop Sequences", stopEntry),
+	)
+	form.Items[0].HintText = "0 to 1, lower is more deterministic"
+	form.Items[1].HintText = "0 to 1, share of likely tokens considered"
+	form.Items[2].HintText = "Longest response, raise it if results are cut off"
+	form.Items[3].HintText = fmt.Sprintf("Up to %d, the model stops before any of them", llm.MaxStopSequences)
+
+	parseFloat := func(name, text string) (*float64, error) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, nil
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s %q is not a number", name, text)
+		}
+		return &value, nil
+	}
+
+	inference := func() (configuration.Inference, error) {
+		var result configuration.Inference
+		var err error
+		if result.Temperature, err = parseFloat("temperature", temperatureEntry.Text); err != nil {
+			return result, err
+		}
+		if result.TopP, err = parseFloat("top-p", topPEntry.Text); err != nil {
+			return result, err
+		}
+		if text := strings.TrimSpace(maxTokensEntry.Text); text != "" {
+			maxTokens, err := strconv.Atoi(text)
+			if err != nil {
+				return result, fmt.Errorf("max tokens %q is not a whole number", text)
+			}
+			result.MaxTokens = &maxTokens
+		}
+		for _, line := range strings.Split(stopEntry.Text, "\n") {
+			if line != "" {
+				result.StopSequences = append(result.StopSequences, line)
+			}
+		}
+		return result, result.Validate()
+	}
+	return form, inference
+}
+
+// ShowActionInferenceDialog edits the inference parameters of an action, which override
+// the global parameters of the settings
+func (p *Panel) ShowActionInferenceDialog(config *configuration.Config, action string) {
+	w := p.Window
+	// Empty fields fall back to the front matter of the prompt file and the global parameters
+	fallback := config.Inference
+	if prompt, err := configuration.LoadPrompt(action); err == nil {
+		fallback = fallback.Merge(prompt.Settings.Inference)
+	}
+	form, inference := newInferenceForm(config.ActionInference[action], fallback)
+
+	parametersDialog := dialog.NewCustomConfirm(
+		fmt.Sprintf("Inference Parameters: %s", action),
+		"Save",
+		"Cancel",
+		widget.NewCard("", "Empty fields use the prompt front matter or the global parameters from the settings, shown in grey", form),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			values, err := inference()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("invalid inference parameters: %v", err), *w)
+				return
+			}
+			config.SetActionInference(action, values)
+			config.Save()
+			fmt.Printf("Inference parameters of action %s: %s\n", action, fallback.Merge(values).Params())
+		},
+		*w,
+	)
+	parametersDialog.Resize(fyne.NewSize(450, 380))
+	parametersDialog.Show()
+}
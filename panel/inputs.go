@@ -0,0 +1,91 @@
+package panel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/megaproaktiv/audionote-config/configuration"
+)
+
+// ShowPromptInputsDialog asks for the inputs declared in the front matter of a prompt.
+// Fields start with the remembered values or the defaults. onSubmit gets the entered values
+// by input name and is not called when the dialog is cancelled
+func (p *Panel) ShowPromptInputsDialog(prompt *configuration.Prompt, remembered map[string]string, onSubmit func(values map[string]string)) {
+	w := p.Window
+
+	var items []*widget.FormItem
+	var getters []func() string
+	for _, input := range prompt.Settings.Inputs {
+		value, ok := remembered[strings.ToLower(input.Name)]
+		if !ok {
+			value = input.Default
+		}
+
+		var field fyne.CanvasObject
+		var get func() string
+		switch input.Type {
+		case configuration.InputMultiline:
+			entry := widget.NewMultiLineEntry()
+			entry.SetText(value)
+			entry.SetMinRowsVisible(3)
+			entry.Wrapping = fyne.TextWrapWord
+			field, get = entry, func() string { return entry.Text }
+		case configuration.InputChoice:
+			choice := widget.NewSelect(input.Choices, nil)
+			if value != "" {
+				choice.SetSelected(value)
+			}
+			field, get = choice, func() string { return choice.Selected }
+		case configuration.InputBool:
+			check := widget.NewCheck("", nil)
+			checked, _ := input.Value(value)
+			isChecked, _ := checked.(bool)
+			check.SetChecked(isChecked)
+			field, get = check, func() string { return strconv.FormatBool(check.Checked) }
+		case configuration.InputNumber:
+			entry := widget.NewEntry()
+			entry.SetText(value)
+			entry.Validator = func(text string) error {
+				_, err := input.Value(text)
+				return err
+			}
+			field, get = entry, func() string { return strings.TrimSpace(entry.Text) }
+		default:
+			entry := widget.NewEntry()
+			entry.SetText(value)
+			field, get = entry, func() string { return entry.Text }
+		}
+
+		item := widget.NewFormItem(input.Title(), field)
+		if input.Label != "" {
+			item.HintText = fmt.Sprintf("{{.Inputs.%s}}", input.Name)
+		}
+		items = append(items, item)
+		getters = append(getters, get)
+	}
+
+	inputsDialog := dialog.NewForm(
+		fmt.Sprintf("Inputs: %s", prompt.Action),
+		"Start",
+		"Cancel",
+		items,
+		func(confirmed bool) {
+			if !confirmed {
+				fmt.Printf("Start of %s cancelled\n", prompt.Action)
+				return
+			}
+			values := map[string]string{}
+			for i, input := range prompt.Settings.Inputs {
+				values[input.Name] = getters[i]()
+			}
+			onSubmit(values)
+		},
+		*w,
+	)
+	inputsDialog.Resize(fyne.NewSize(450, 150+float32(len(items))*60))
+	inputsDialog.Show()
+}
@@ -0,0 +1,59 @@
+package panel
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/megaproaktiv/audionote-config/jobs"
+)
+
+// ShowResumeJobsDialog lists jobs interrupted by a previous app exit
+// and lets the user resume or discard each of them
+func (p *Panel) ShowResumeJobsDialog(incomplete []*jobs.Job, onResume func(job *jobs.Job)) {
+	w := *p.Window
+	rows := container.NewVBox()
+	var resumeDialog dialog.Dialog
+
+	for _, job := range incomplete {
+		info := widget.NewLabel(fmt.Sprintf("%s\nAction: %s, Language: %s\nStage: %s, last update: %s",
+			filepath.Base(job.InputFile), job.Action, job.Language, job.Stage, job.UpdatedAt.Format("2006-01-02 15:04")))
+
+		var row *fyne.Container
+		resumeButton := widget.NewButtonWithIcon("Resume", theme.MediaPlayIcon(), func() {
+			fmt.Printf("Resuming job %s at stage %s\n", job.ID, job.Stage)
+			onResume(job)
+			rows.Remove(row)
+			if len(rows.Objects) == 0 {
+				resumeDialog.Hide()
+			}
+		})
+		discardButton := widget.NewButtonWithIcon("Discard", theme.DeleteIcon(), func() {
+			if err := jobs.Remove(job.ID); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to discard job: %v", err), w)
+				return
+			}
+			fmt.Printf("Discarded job %s\n", job.ID)
+			rows.Remove(row)
+			if len(rows.Objects) == 0 {
+				resumeDialog.Hide()
+			}
+		})
+		row = container.NewBorder(nil, widget.NewSeparator(), nil,
+			container.NewVBox(resumeButton, discardButton), info)
+		rows.Add(row)
+	}
+
+	content := container.NewBorder(
+		widget.NewLabel("These jobs were interrupted when the app was closed:"),
+		nil, nil, nil,
+		container.NewVScroll(rows),
+	)
+	resumeDialog = dialog.NewCustom("Resume Jobs", "Later", content, w)
+	resumeDialog.Resize(fyne.NewSize(550, 400))
+	resumeDialog.Show()
+}
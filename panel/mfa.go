@@ -0,0 +1,51 @@
+package panel
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// PromptMFAToken asks for the code of an MFA device in a dialog and blocks until it is
+// entered or cancelled, so it must not be called from the UI thread
+func (p *Panel) PromptMFAToken(profile, serial string) (string, error) {
+	w := *p.Window
+	result := make(chan string, 1)
+
+	fyne.Do(func() {
+		codeEntry := widget.NewEntry()
+		codeEntry.SetPlaceHolder("123456")
+		codeEntry.Validator = func(text string) error {
+			text = strings.TrimSpace(text)
+			if len(text) != 6 || strings.Trim(text, "0123456789") != "" {
+				return fmt.Errorf("enter the 6 digit code")
+			}
+			return nil
+		}
+
+		items := []*widget.FormItem{
+			widget.NewFormItem("Profile", widget.NewLabel(profile)),
+			widget.NewFormItem("MFA Device", widget.NewLabel(serial)),
+			widget.NewFormItem("Code", codeEntry),
+		}
+		mfaDialog := dialog.NewForm("MFA Code Required", "OK", "Cancel", items, func(confirmed bool) {
+			if confirmed {
+				result <- strings.TrimSpace(codeEntry.Text)
+				return
+			}
+			result <- ""
+		}, w)
+		mfaDialog.Resize(fyne.NewSize(450, 200))
+		mfaDialog.Show()
+		w.Canvas().Focus(codeEntry)
+	})
+
+	code := <-result
+	if code == "" {
+		return "", fmt.Errorf("MFA code entry cancelled")
+	}
+	return code, nil
+}
@@ -0,0 +1,143 @@
+package panel
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/megaproaktiv/audionote-config/llm"
+)
+
+// newModelPicker creates a searchable dropdown of Bedrock models with details and a test button.
+// It starts with the known models, Load Models fills it with the foundation models and
+// inference profiles of the region. Typing filters the list by model ID, name and provider.
+// profile and region return the current AWS profile and Bedrock region of the surrounding form.
+// The returned function gives the entered model ID
+func newModelPicker(current string, profile func() string, region func() string) (fyne.CanvasObject, func() string) {
+	byID := map[string]llm.ModelInfo{}
+	allOptions := llm.KnownModels
+
+	modelEntry := widget.NewSelectEntry(allOptions)
+	modelEntry.SetText(current)
+	modelEntry.SetPlaceHolder("Select or search a Bedrock model ID")
+
+	details := widget.NewLabel("")
+	details.Wrapping = fyne.TextWrapWord
+	status := widget.NewLabel("")
+	status.Wrapping = fyne.TextWrapWord
+
+	selectedModel := func() string {
+		return strings.TrimSpace(modelEntry.Text)
+	}
+
+	// showDetails shows modality, context window and access status of a loaded model
+	showDetails := func(id string) {
+		info, ok := byID[id]
+		if !ok {
+			if tokens := llm.ContextTokens(id); tokens > 0 {
+				details.SetText(fmt.Sprintf("Context: %s", llm.ModelInfo{ContextTokens: tokens}.Context()))
+			} else {
+				details.SetText("")
+			}
+			return
+		}
+		kind := "foundation model"
+		if info.InferenceProfile {
+			kind = "inference profile for " + info.FoundationModel
+		}
+		summary := fmt.Sprintf("%s (%s, %s)\nModality: %s\nContext: %s",
+			info.Name, info.Provider, kind, info.Modalities(), info.Context())
+		details.SetText(summary + "\nAccess: checking...")
+
+		awsProfile, bedrockRegion := profile(), region()
+		go func() {
+			access, err := llm.ModelAccess(id, awsProfile, bedrockRegion)
+			if err != nil {
+				fmt.Printf("Error checking access to model %s: %v\n", id, err)
+				access = "unknown"
+			}
+			fyne.Do(func() {
+				// The selection may have changed in the meantime
+				if selectedModel() == id {
+					details.SetText(summary + "\nAccess: " + access)
+				}
+			})
+		}()
+	}
+
+	modelEntry.OnChanged = func(text string) {
+		search := strings.ToLower(strings.TrimSpace(text))
+		if _, exact := byID[strings.TrimSpace(text)]; exact || search == "" {
+			modelEntry.SetOptions(allOptions)
+		} else {
+			var filtered []string
+			for _, id := range allOptions {
+				info := byID[id]
+				if strings.Contains(strings.ToLower(id+" "+info.Name+" "+info.Provider), search) {
+					filtered = append(filtered, id)
+				}
+			}
+			modelEntry.SetOptions(filtered)
+		}
+		showDetails(strings.TrimSpace(text))
+	}
+
+	var loadButton *widget.Button
+	loadButton = widget.NewButtonWithIcon("Load Models", theme.ViewRefreshIcon(), func() {
+		awsProfile, bedrockRegion := profile(), region()
+		loadButton.Disable()
+		status.SetText("Loading models...")
+		go func() {
+			loaded, err := llm.ListModels(awsProfile, bedrockRegion)
+			fyne.Do(func() {
+				loadButton.Enable()
+				if err != nil {
+					status.SetText(fmt.Sprintf("✗ %v", err))
+					return
+				}
+				byID = map[string]llm.ModelInfo{}
+				allOptions = nil
+				for _, info := range loaded {
+					byID[info.ID] = info
+					allOptions = append(allOptions, info.ID)
+				}
+				status.SetText(fmt.Sprintf("%d models available, type to search", len(loaded)))
+				modelEntry.SetOptions(allOptions)
+				showDetails(selectedModel())
+			})
+		}()
+	})
+
+	var testButton *widget.Button
+	testButton = widget.NewButtonWithIcon("Test", theme.MediaPlayIcon(), func() {
+		model := selectedModel()
+		if model == "" {
+			model = llm.DefaultModel
+		}
+		awsProfile, bedrockRegion := profile(), region()
+		testButton.Disable()
+		status.SetText(fmt.Sprintf("Invoking %s...", model))
+		go func() {
+			err := llm.TestModel(model, awsProfile, bedrockRegion)
+			fyne.Do(func() {
+				testButton.Enable()
+				if err != nil {
+					status.SetText(fmt.Sprintf("✗ %v\n\nCheck that model access is granted in the Bedrock console for this region.", err))
+					return
+				}
+				status.SetText(fmt.Sprintf("✓ Model %s is accessible", model))
+			})
+		}()
+	})
+
+	showDetails(selectedModel())
+	picker := container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(loadButton, testButton), modelEntry),
+		details,
+		status,
+	)
+	return picker, selectedModel
+}
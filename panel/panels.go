@@ -10,11 +10,17 @@ type Panel struct {
 	CurrentDir           string
 	PromptLabel          *widget.Label
 	PromptEditor         *widget.Entry
+	PromptStatus         *widget.Label
 	ResultField          *widget.Entry
 	SavePromptButton     *widget.Button
+	InferenceButton      *widget.Button
+	HistoryButton        *widget.Button
+	RestoreDefaultButton *widget.Button
 	CopyResultButton     *widget.Button
 	OutputField          *widget.Entry
 	OutputPathSelector   *widget.Button
 	OutputDirectoryLabel *widget.Label
 	Window               *fyne.Window
+	// RefreshActions reloads the actions, e.g. after the team prompt directory changed
+	RefreshActions func()
 }
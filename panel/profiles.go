@@ -0,0 +1,148 @@
+package panel
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	awsutil "github.com/megaproaktiv/audionote-config/aws"
+)
+
+// ssoLoginMu serializes SSO logins, so a profile is not logged in twice at the same time
+var ssoLoginMu sync.Mutex
+
+// newProfileSelect creates an entry for the AWS profile with a dropdown of the profiles in the
+// shared AWS config files. Type and region of the profile are shown as hint below the entry.
+// Names not in the files, e.g. of credentials from the environment, can be typed.
+// onChanged may be nil, it is called when a profile is picked from the dropdown.
+// The returned function gives the entered profile name
+func newProfileSelect(current string, onChanged func(name string)) (fyne.CanvasObject, func() string) {
+	profiles, err := awsutil.ListProfiles()
+	if err != nil {
+		fmt.Printf("Error reading AWS profiles: %v\n", err)
+	}
+
+	var labels []string
+	names := map[string]string{}
+	hints := map[string]string{}
+	for _, profile := range profiles {
+		label := profile.Label()
+		labels = append(labels, label)
+		names[label] = profile.Name
+		hints[profile.Name] = label
+	}
+
+	hintLabel := widget.NewLabel("")
+	hintLabel.TextStyle.Italic = true
+	hintLabel.Wrapping = fyne.TextWrapWord
+	showHint := func(name string) {
+		switch {
+		case hints[name] != "":
+			hintLabel.SetText(hints[name])
+		case len(labels) == 0:
+			hintLabel.SetText(fmt.Sprintf("No profiles found in %s, type a profile name or configure one with 'aws configure'", awsutil.ConfigFile()))
+		case name != "":
+			hintLabel.SetText("Not in the shared config files, e.g. credentials from the environment")
+		default:
+			hintLabel.SetText("")
+		}
+	}
+
+	profileEntry := widget.NewSelectEntry(labels)
+	profileEntry.SetPlaceHolder("Select or type an AWS profile")
+	// Set before OnChanged, the initial value does not start a login
+	profileEntry.SetText(current)
+	showHint(current)
+	profileEntry.OnChanged = func(text string) {
+		if name, ok := names[text]; ok {
+			// The dropdown shows the labels, the entry keeps the profile name
+			profileEntry.SetText(name)
+			if onChanged != nil {
+				onChanged(name)
+			}
+			return
+		}
+		showHint(strings.TrimSpace(text))
+	}
+
+	selectedName := func() string {
+		name := strings.TrimSpace(profileEntry.Text)
+		if name == "" {
+			name = "default"
+		}
+		return name
+	}
+	return container.NewVBox(profileEntry, hintLabel), selectedName
+}
+
+// EnsureSSOLogin starts the SSO device authorization flow if the profile is an SSO profile
+// without a valid token. It shows the verification URL and code in a dialog and blocks until
+// the login is approved, fails or is cancelled, so it must not be called from the UI thread
+func (p *Panel) EnsureSSOLogin(profileName string) error {
+	ssoLoginMu.Lock()
+	defer ssoLoginMu.Unlock()
+
+	profile, err := awsutil.FindProfile(profileName)
+	if err != nil || !awsutil.SSOLoginRequired(profile) {
+		// Unknown profiles are left to the SDK, which reports a missing profile
+		return nil
+	}
+	fmt.Printf("SSO token of profile %s is missing or expired, starting login\n", profileName)
+
+	w := *p.Window
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var loginDialog dialog.Dialog
+	err = awsutil.SSOLogin(ctx, profile, func(authorization awsutil.DeviceAuthorization) {
+		fyne.Do(func() {
+			verificationURL := authorization.VerificationURIComplete
+			if verificationURL == "" {
+				verificationURL = authorization.VerificationURI
+			}
+			parsedURL, _ := url.Parse(verificationURL)
+
+			codeEntry := widget.NewEntry()
+			codeEntry.SetText(authorization.UserCode)
+			codeEntry.TextStyle = fyne.TextStyle{Monospace: true}
+
+			content := container.NewVBox(
+				widget.NewRichTextFromMarkdown(fmt.Sprintf("The SSO session of profile **%s** has expired.\n\nOpen the verification page, check that it shows the code below and approve the login.", profileName)),
+				widget.NewHyperlink(authorization.VerificationURI, parsedURL),
+				widget.NewLabel("Code:"),
+				codeEntry,
+				widget.NewButtonWithIcon("Open Browser", theme.ComputerIcon(), func() {
+					if err := fyne.CurrentApp().OpenURL(parsedURL); err != nil {
+						fmt.Printf("Could not open browser: %v\n", err)
+					}
+				}),
+				widget.NewLabel(fmt.Sprintf("Waiting for approval until %s...", authorization.ExpiresAt.Format("15:04"))),
+			)
+			loginDialog = dialog.NewCustom("AWS SSO Login", "Cancel", content, w)
+			loginDialog.SetOnClosed(cancel)
+			loginDialog.Resize(fyne.NewSize(450, 300))
+			loginDialog.Show()
+		})
+	})
+
+	cancelled := ctx.Err() != nil
+	fyne.Do(func() {
+		if loginDialog != nil {
+			loginDialog.Hide()
+		}
+		if err != nil && !cancelled {
+			dialog.ShowError(err, w)
+		}
+	})
+	if err != nil {
+		fmt.Printf("SSO login for profile %s failed: %v\n", profileName, err)
+	}
+	return err
+}
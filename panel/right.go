@@ -13,12 +13,18 @@ func (p *Panel) RightPanel() *container.AppTabs {
 			container.NewBorder(
 				// Top: Just the label
 				container.NewPadded(p.PromptLabel),
-				// Bottom: Centered save button
+				// Bottom: Template status and centered save button
 				container.NewPadded(
-					container.NewHBox(
-						layout.NewSpacer(),
-						p.SavePromptButton,
-						layout.NewSpacer(),
+					container.NewVBox(
+						p.PromptStatus,
+						container.NewHBox(
+							layout.NewSpacer(),
+							p.SavePromptButton,
+							p.InferenceButton,
+							p.HistoryButton,
+							p.RestoreDefaultButton,
+							layout.NewSpacer(),
+						),
 					),
 				),
 				// Left, Right: nil
@@ -0,0 +1,341 @@
+package panel
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	awsutil "github.com/megaproaktiv/audionote-config/aws"
+	"github.com/megaproaktiv/audionote-config/configuration"
+	"github.com/megaproaktiv/audionote-config/llm"
+	"github.com/megaproaktiv/audionote-config/translate"
+)
+
+// setupTool is an external program the app calls
+type setupTool struct {
+	name     string
+	purpose  string
+	required bool
+}
+
+// setupTools are checked by the last step of the setup wizard
+var setupTools = []setupTool{
+	{name: "ffmpeg", purpose: "converts m4a recordings to mp3", required: false},
+}
+
+// wizardStep is one page of the setup wizard
+type wizardStep struct {
+	title       string
+	description string
+	content     fyne.CanvasObject
+	onShow      func()
+}
+
+// ShowSetupWizard guides through the first configuration: AWS profile, S3 bucket,
+// Bedrock model, output folder and external tools. Nothing is saved before Finish,
+// onFinished is called after the configuration is saved
+func (p *Panel) ShowSetupWizard(config *configuration.Config, onFinished func()) {
+	w := *p.Window
+
+	//--------------------------------------------------------------
+	// AWS profile
+	//--------------------------------------------------------------
+	profileSelect, selectedProfile := newProfileSelect(config.AWSProfile, func(name string) {
+		go p.EnsureSSOLogin(name)
+	})
+
+	identityLabel := widget.NewLabel("")
+	identityLabel.Wrapping = fyne.TextWrapWord
+
+	bucketEntry := widget.NewEntry()
+	bucketEntry.SetText(config.S3Bucket)
+	bucketEntry.SetPlaceHolder("Enter S3 bucket name (e.g., my-audio-bucket)")
+
+	checkIdentityButton := widget.NewButtonWithIcon("Check Identity", theme.ConfirmIcon(), nil)
+	checkIdentityButton.OnTapped = func() {
+		profile := selectedProfile()
+		checkIdentityButton.Disable()
+		identityLabel.SetText(fmt.Sprintf("Checking profile %s...", profile))
+		go func() {
+			session, err := awsutil.GetSession(context.Background(), profile)
+			fyne.Do(func() {
+				checkIdentityButton.Enable()
+				if err != nil {
+					identityLabel.SetText(fmt.Sprintf("✗ %v", err))
+					return
+				}
+				region := session.Config.Region
+				identityLabel.SetText(fmt.Sprintf("✓ Account: %s\nIdentity: %s\nRegion: %s", session.Account, session.ARN, region))
+				// Suggest a bucket name that is unique per account and region
+				if strings.TrimSpace(bucketEntry.Text) == "" {
+					bucketEntry.SetText(fmt.Sprintf("audionote-%s-%s", session.Account, region))
+				}
+			})
+		}()
+	}
+
+	profileStep := container.NewVBox(
+		widget.NewLabel("AWS Profile:"),
+		container.NewBorder(nil, nil, nil, checkIdentityButton, profileSelect),
+		identityLabel,
+	)
+
+	//--------------------------------------------------------------
+	// S3 bucket
+	//--------------------------------------------------------------
+	bucketStatus := widget.NewLabel("")
+	bucketStatus.Wrapping = fyne.TextWrapWord
+
+	// Region found by the last successful bucket check, cached on finish
+	// Finish needs a successful check of the bucket with the selected profile
+	var checkedBucket, checkedRegion, checkedProfile string
+
+	wizardStorage := func() translate.Storage {
+		return translate.NewStorage(bucketEntry.Text, config.S3Prefix, config.S3SSE, config.S3KMSKeyID)
+	}
+
+	createBucketButton := widget.NewButtonWithIcon("Create Bucket...", theme.ContentAddIcon(), nil)
+	createBucketButton.Disable()
+	checkBucketButton := widget.NewButtonWithIcon("Check", theme.ConfirmIcon(), nil)
+	checkBucketButton.OnTapped = func() {
+		bucketStorage := wizardStorage()
+		profile := selectedProfile()
+		checkBucketButton.Disable()
+		createBucketButton.Disable()
+		bucketStatus.SetText(fmt.Sprintf("Checking bucket %s...", bucketStorage.Bucket))
+		go func() {
+			exists, region, message, err := validateS3Bucket(bucketStorage, profile)
+			fyne.Do(func() {
+				checkBucketButton.Enable()
+				bucketStatus.SetText(message)
+				if exists {
+					checkedBucket, checkedRegion, checkedProfile = bucketStorage.Bucket, region, profile
+				}
+				if err != nil && strings.Contains(err.Error(), "NoSuchBucket") {
+					createBucketButton.Enable()
+				}
+			})
+		}()
+	}
+	createBucketButton.OnTapped = func() {
+		days := translate.LifecycleDays(config.S3Retention, config.S3RetentionDays)
+		p.showCreateBucketDialog(wizardStorage(), selectedProfile(), days, checkBucketButton.OnTapped)
+	}
+
+	bucketStep := container.NewVBox(
+		widget.NewLabel("S3 Bucket:"),
+		container.NewBorder(nil, nil, nil, container.NewHBox(checkBucketButton, createBucketButton), bucketEntry),
+		bucketStatus,
+	)
+
+	//--------------------------------------------------------------
+	// Bedrock model
+	//--------------------------------------------------------------
+	bedrockRegionEntry := widget.NewEntry()
+	bedrockRegionEntry.SetText(config.BedrockRegion)
+	bedrockRegionEntry.SetPlaceHolder("Region of the AWS profile (e.g., eu-central-1)")
+
+	modelPicker, selectedModel := newModelPicker(config.Model, selectedProfile, func() string {
+		return strings.TrimSpace(bedrockRegionEntry.Text)
+	})
+
+	modelStep := container.NewVBox(
+		widget.NewLabel("Bedrock Region:"),
+		bedrockRegionEntry,
+		widget.NewLabel("Bedrock Model:"),
+		modelPicker,
+	)
+
+	//--------------------------------------------------------------
+	// Output folder
+	//--------------------------------------------------------------
+	outputDir := filepath.Dir(config.OutputPath)
+	outputName := filepath.Base(config.OutputPath)
+	outputDirLabel := widget.NewLabel(outputDir)
+	outputDirLabel.Wrapping = fyne.TextWrapWord
+
+	chooseFolderButton := widget.NewButtonWithIcon("Choose Folder...", theme.FolderOpenIcon(), func() {
+		folderDialog := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				fmt.Printf("Error selecting output folder: %v\n", err)
+				return
+			}
+			if uri == nil {
+				return
+			}
+			outputDir = uri.Path()
+			outputDirLabel.SetText(outputDir)
+			fmt.Printf("Output folder selected: %s\n", outputDir)
+		}, w)
+		if configuration.DirExists(outputDir) {
+			if lister, err := storage.ListerForURI(storage.NewFileURI(outputDir)); err == nil {
+				folderDialog.SetLocation(lister)
+			}
+		}
+		folderDialog.Show()
+	})
+
+	outputStep := container.NewVBox(
+		widget.NewLabel("Output Folder:"),
+		container.NewBorder(nil, nil, nil, chooseFolderButton, outputDirLabel),
+	)
+
+	//--------------------------------------------------------------
+	// External tools
+	//--------------------------------------------------------------
+	toolsBox := container.NewVBox()
+	checkTools := func() {
+		toolsBox.RemoveAll()
+		for _, tool := range setupTools {
+			text := ""
+			path, err := exec.LookPath(tool.name)
+			switch {
+			case err == nil:
+				text = fmt.Sprintf("✓ %s (%s): %s", tool.name, tool.purpose, path)
+			case tool.required:
+				text = fmt.Sprintf("✗ %s (%s) not found, install it and add it to the PATH", tool.name, tool.purpose)
+			default:
+				text = fmt.Sprintf("ℹ %s (%s) not found, optional", tool.name, tool.purpose)
+			}
+			label := widget.NewLabel(text)
+			label.Wrapping = fyne.TextWrapWord
+			toolsBox.Add(label)
+		}
+	}
+
+	//--------------------------------------------------------------
+	// Wizard navigation
+	//--------------------------------------------------------------
+	steps := []wizardStep{
+		{
+			title:       "AWS Profile",
+			description: "Select the AWS profile used for S3, AWS Transcribe and Bedrock, and check that its credentials work.",
+			content:     profileStep,
+		},
+		{
+			title:       "S3 Bucket",
+			description: "Audio files are uploaded to this bucket for AWS Transcribe. Check an existing bucket or create a new one.",
+			content:     bucketStep,
+		},
+		{
+			title:       "Bedrock Model",
+			description: "Select the model that processes the transcript. Test it to make sure your account has access.",
+			content:     modelStep,
+		},
+		{
+			title:       "Output Folder",
+			description: fmt.Sprintf("Results are written as %s to this folder.", outputName),
+			content:     outputStep,
+		},
+		{
+			title:       "External Tools",
+			description: "These programs are called by the app.",
+			content:     toolsBox,
+			onShow:      checkTools,
+		},
+	}
+
+	stepTitle := widget.NewRichTextFromMarkdown("")
+	stepTitle.Wrapping = fyne.TextWrapWord
+	stepContent := container.NewStack()
+	backButton := widget.NewButtonWithIcon("Back", theme.NavigateBackIcon(), nil)
+	nextButton := widget.NewButtonWithIcon("Next", theme.NavigateNextIcon(), nil)
+	skipButton := widget.NewButton("Skip Setup", nil)
+
+	bucketChecked := func() bool {
+		return checkedBucket != "" && checkedBucket == wizardStorage().Bucket && checkedProfile == selectedProfile()
+	}
+
+	current := 0
+	showStep := func(index int) {
+		current = index
+		step := steps[index]
+		description := step.description
+		if index == len(steps)-1 && !bucketChecked() {
+			description += "\n\n**Check the S3 bucket with the selected AWS profile before finishing the setup.**"
+		}
+		stepTitle.ParseMarkdown(fmt.Sprintf("## Step %d of %d: %s\n\n%s", index+1, len(steps), step.title, description))
+		stepContent.Objects = []fyne.CanvasObject{step.content}
+		stepContent.Refresh()
+		if index == 0 {
+			backButton.Disable()
+		} else {
+			backButton.Enable()
+		}
+		nextButton.Enable()
+		if index == len(steps)-1 {
+			nextButton.SetText("Finish")
+			if !bucketChecked() {
+				nextButton.Disable()
+			}
+		} else {
+			nextButton.SetText("Next")
+		}
+		if step.onShow != nil {
+			step.onShow()
+		}
+	}
+
+	finish := func() {
+		bucket := strings.TrimSpace(bucketEntry.Text)
+		model := selectedModel()
+		if model == "" {
+			model = llm.DefaultModel
+		}
+
+		// The cached bucket region is only valid for the checked bucket
+		if bucket != config.S3Bucket {
+			config.S3BucketRegion = ""
+		}
+		if checkedBucket == bucket && checkedRegion != "" {
+			config.S3BucketRegion = checkedRegion
+		}
+		config.AWSProfile = selectedProfile()
+		config.S3Bucket = bucket
+		config.Model = model
+		config.BedrockRegion = strings.TrimSpace(bedrockRegionEntry.Text)
+		config.OutputPath = filepath.Join(outputDir, outputName)
+		config.FirstRun = false
+		config.Save()
+
+		fmt.Printf("Setup finished - S3 Bucket: %s, AWS Profile: %s, Model: %s, Output Path: %s\n",
+			config.S3Bucket, config.AWSProfile, config.Model, config.OutputPath)
+		if onFinished != nil {
+			onFinished()
+		}
+	}
+
+	wizardDialog := dialog.NewCustomWithoutButtons("Audio Note LLM Setup",
+		container.NewBorder(stepTitle, container.NewHBox(skipButton, layout.NewSpacer(), backButton, nextButton), nil, nil, container.NewVScroll(stepContent)), w)
+
+	backButton.OnTapped = func() {
+		if current > 0 {
+			showStep(current - 1)
+		}
+	}
+	nextButton.OnTapped = func() {
+		if current < len(steps)-1 {
+			showStep(current + 1)
+			return
+		}
+		wizardDialog.Hide()
+		finish()
+	}
+	skipButton.OnTapped = func() {
+		wizardDialog.Hide()
+		fmt.Println("Setup skipped, run it again from Settings > Setup Wizard...")
+	}
+
+	showStep(0)
+	wizardDialog.Resize(fyne.NewSize(600, 480))
+	wizardDialog.Show()
+}
@@ -0,0 +1,54 @@
+package panel
+
+import (
+	"context"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	awsutil "github.com/megaproaktiv/audionote-config/aws"
+	"github.com/megaproaktiv/audionote-config/configuration"
+	"github.com/megaproaktiv/audionote-config/translate"
+)
+
+// teamSourceLabels and teamSourceValues map the team prompt selector to config values
+var teamSourceLabels = []string{"None", "Shared directory", "S3 prefix in the bucket"}
+var teamSourceValues = []string{configuration.TeamSourceNone, configuration.TeamSourceDirectory, configuration.TeamSourceS3}
+
+// SyncTeamPrompts downloads the team prompts from the S3 prefix in the background
+// onDone runs in the UI goroutine, also when the team prompts are not in S3
+func (p *Panel) SyncTeamPrompts(config *configuration.Config, onDone func(err error)) {
+	if !config.TeamPromptsFromS3() {
+		onDone(nil)
+		return
+	}
+	bucket, prefix, awsProfile := config.S3Bucket, config.TeamPromptS3Prefix, config.AWSProfile
+	region := config.BucketRegion(bucket)
+	go func() {
+		err := syncTeamPrompts(bucket, prefix, awsProfile, region)
+		if err != nil {
+			fmt.Printf("Error syncing team prompts: %v\n", err)
+		}
+		fyne.Do(func() {
+			onDone(err)
+		})
+	}()
+}
+
+// syncTeamPrompts mirrors the team prompts below prefix into the team prompt cache
+func syncTeamPrompts(bucket, prefix, awsProfile, region string) error {
+	ctx := context.Background()
+	session, err := awsutil.GetSession(ctx, awsProfile)
+	if err != nil {
+		return err
+	}
+	if region == "" {
+		region, err = awsutil.BucketRegion(ctx, session.Config, bucket)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = translate.SyncPrompts(ctx, session.S3(region), bucket, prefix, configuration.TeamCacheDir())
+	// Also a failed sync may have changed some of the files
+	configuration.InvalidatePrompts()
+	return err
+}
@@ -0,0 +1,127 @@
+package panel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/megaproaktiv/audionote-config/configuration"
+	"github.com/megaproaktiv/audionote-config/translate"
+)
+
+// ShowTranscribeJobBrowser lists the Transcribe jobs of this app and lets the user
+// import a completed transcript into the local cache or delete a job with its S3 objects
+func (p *Panel) ShowTranscribeJobBrowser(config *configuration.Config) {
+	w := *p.Window
+	storage := translate.NewStorage(config.S3Bucket, config.S3Prefix, config.S3SSE, config.S3KMSKeyID)
+	var remoteJobs []translate.RemoteJob
+
+	statusLabel := widget.NewLabel("Loading transcription jobs...")
+	var jobList *widget.List
+
+	loadJobs := func() {
+		statusLabel.SetText("Loading transcription jobs...")
+		go func() {
+			ctx := context.Background()
+			if _, err := translate.InitClient(ctx, config.AWSProfile, config.S3Bucket, config.S3BucketRegion); err != nil {
+				fyne.Do(func() {
+					statusLabel.SetText(fmt.Sprintf("Could not load AWS profile %s: %v", config.AWSProfile, err))
+				})
+				return
+			}
+			loaded, err := translate.ListAppJobs(ctx, translate.Client)
+			fyne.Do(func() {
+				if err != nil {
+					statusLabel.SetText(fmt.Sprintf("Error: %v", err))
+					return
+				}
+				remoteJobs = loaded
+				statusLabel.SetText(fmt.Sprintf("%d transcription jobs of this app", len(remoteJobs)))
+				jobList.Refresh()
+			})
+		}()
+	}
+
+	importJob := func(job translate.RemoteJob) {
+		if job.Status != "COMPLETED" {
+			dialog.ShowInformation("Import", fmt.Sprintf("Job '%s' is %s and has no transcript yet.", job.Name, job.Status), w)
+			return
+		}
+		go func() {
+			file, err := translate.ImportTranscript(context.Background(), translate.S3Client, job.Name, storage)
+			fyne.Do(func() {
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("failed to import transcript: %v", err), w)
+					return
+				}
+				dialog.ShowInformation("Import", fmt.Sprintf("Transcript imported to\n%s", file), w)
+			})
+		}()
+	}
+
+	deleteJob := func(job translate.RemoteJob) {
+		message := fmt.Sprintf("Delete transcription job '%s'\nand its audio and transcript in S3?", job.Name)
+		dialog.ShowConfirm("Delete Job", message, func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			go func() {
+				err := translate.DeleteJob(context.Background(), translate.Client, translate.S3Client, job.Name)
+				fyne.Do(func() {
+					if err != nil {
+						dialog.ShowError(err, w)
+						return
+					}
+					loadJobs()
+				})
+			}()
+		}, w)
+	}
+
+	jobList = widget.NewList(
+		func() int {
+			return len(remoteJobs)
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil,
+				container.NewHBox(
+					widget.NewButtonWithIcon("Import", theme.DownloadIcon(), nil),
+					widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), nil),
+				),
+				widget.NewLabel("job\ndetails"),
+			)
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			job := remoteJobs[id]
+			row := item.(*fyne.Container)
+			label := row.Objects[0].(*widget.Label)
+			buttons := row.Objects[1].(*fyne.Container)
+
+			details := fmt.Sprintf("%s\n%s, %s, created %s", job.Name, job.Status, job.Language, job.CreatedAt.Format("2006-01-02 15:04"))
+			if duration := job.Duration(); duration > 0 {
+				details += fmt.Sprintf(", took %s", duration.Round(time.Second))
+			}
+			label.SetText(details)
+			buttons.Objects[0].(*widget.Button).OnTapped = func() { importJob(job) }
+			buttons.Objects[1].(*widget.Button).OnTapped = func() { deleteJob(job) }
+		},
+	)
+
+	refreshButton := widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), loadJobs)
+
+	content := container.NewBorder(
+		container.NewBorder(nil, nil, nil, refreshButton, statusLabel),
+		nil, nil, nil,
+		jobList,
+	)
+
+	browser := dialog.NewCustom("Transcribe Jobs", "Close", content, w)
+	browser.Resize(fyne.NewSize(750, 500))
+	browser.Show()
+	loadJobs()
+}
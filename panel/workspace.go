@@ -0,0 +1,26 @@
+package panel
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2/dialog"
+	"github.com/megaproaktiv/audionote-config/workspace"
+)
+
+// ShowCleanWorkspaceDialog asks for confirmation and removes all staged and derived files
+func (p *Panel) ShowCleanWorkspaceDialog() {
+	w := *p.Window
+	message := fmt.Sprintf("Remove all staged audio files and cached transcripts from\n%s?", workspace.Dir())
+	dialog.ShowConfirm("Clean Workspace", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		removed, err := workspace.Clean()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to clean workspace: %v", err), w)
+			fmt.Printf("Error cleaning workspace: %v\n", err)
+			return
+		}
+		dialog.ShowInformation("Workspace Cleaned", fmt.Sprintf("Removed %d files from the workspace.", removed), w)
+	}, w)
+}
@@ -0,0 +1,130 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// CreateBucket creates a bucket for this app in the given region and sets it up:
+// public access blocked, default encryption, the bucket policy AWS Transcribe needs
+// to read media and write output below the prefix, and with lifecycleDays > 0 a lifecycle rule.
+// s3Client must be configured for region. accountID and partition identify the caller's account
+func CreateBucket(ctx context.Context, s3Client *s3.Client, storage Storage, region, accountID, partition string, lifecycleDays int) error {
+	bucket := storage.Bucket
+	input := &s3.CreateBucketInput{
+		Bucket: aws.String(bucket),
+	}
+	// us-east-1 is the default location and must not be passed as constraint
+	if region != "us-east-1" {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(region),
+		}
+	}
+	fmt.Printf("Creating bucket %s in region %s...\n", bucket, region)
+	if _, err := s3Client.CreateBucket(ctx, input); err != nil {
+		return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+	}
+
+	fmt.Printf("Blocking public access for bucket %s\n", bucket)
+	_, err := s3Client.PutPublicAccessBlock(ctx, &s3.PutPublicAccessBlockInput{
+		Bucket: aws.String(bucket),
+		PublicAccessBlockConfiguration: &types.PublicAccessBlockConfiguration{
+			BlockPublicAcls:       aws.Bool(true),
+			BlockPublicPolicy:     aws.Bool(true),
+			IgnorePublicAcls:      aws.Bool(true),
+			RestrictPublicBuckets: aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to block public access for bucket %s: %w", bucket, err)
+	}
+
+	// Default encryption follows the storage settings, SSE-S3 if none is configured
+	encryption := &types.ServerSideEncryptionByDefault{
+		SSEAlgorithm: types.ServerSideEncryptionAes256,
+	}
+	if storage.SSE == SSEKMS {
+		encryption.SSEAlgorithm = types.ServerSideEncryptionAwsKms
+		encryption.KMSMasterKeyID = aws.String(storage.KMSKeyID)
+	}
+	fmt.Printf("Setting default encryption %s for bucket %s\n", encryption.SSEAlgorithm, bucket)
+	_, err = s3Client.PutBucketEncryption(ctx, &s3.PutBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+		ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration{
+			Rules: []types.ServerSideEncryptionRule{{
+				ApplyServerSideEncryptionByDefault: encryption,
+				BucketKeyEnabled:                   aws.Bool(storage.SSE == SSEKMS),
+			}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set default encryption for bucket %s: %w", bucket, err)
+	}
+
+	policy, err := TranscribeBucketPolicy(storage, accountID, partition)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Adding AWS Transcribe bucket policy to bucket %s\n", bucket)
+	_, err = s3Client.PutBucketPolicy(ctx, &s3.PutBucketPolicyInput{
+		Bucket: aws.String(bucket),
+		Policy: aws.String(policy),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set bucket policy for bucket %s: %w", bucket, err)
+	}
+
+	if lifecycleDays > 0 {
+		if err := ApplyLifecycleRule(ctx, s3Client, storage, lifecycleDays); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Bucket %s is set up\n", bucket)
+	return nil
+}
+
+// TranscribeBucketPolicy returns a bucket policy that lets AWS Transcribe, acting for
+// the given account, read media and write output below the storage prefix
+func TranscribeBucketPolicy(storage Storage, accountID, partition string) (string, error) {
+	if partition == "" {
+		partition = "aws"
+	}
+	resource := fmt.Sprintf("arn:%s:s3:::%s/%s*", partition, storage.Bucket, storage.Prefix)
+	condition := map[string]any{
+		"StringEquals": map[string]string{"aws:SourceAccount": accountID},
+	}
+	principal := map[string]string{"Service": "transcribe.amazonaws.com"}
+
+	policy := map[string]any{
+		"Version": "2012-10-17",
+		"Statement": []map[string]any{
+			{
+				"Sid":       "AllowTranscribeReadMedia",
+				"Effect":    "Allow",
+				"Principal": principal,
+				"Action":    "s3:GetObject",
+				"Resource":  resource,
+				"Condition": condition,
+			},
+			{
+				"Sid":       "AllowTranscribeWriteOutput",
+				"Effect":    "Allow",
+				"Principal": principal,
+				"Action":    "s3:PutObject",
+				"Resource":  fmt.Sprintf("arn:%s:s3:::%s/%s*", partition, storage.Bucket, storage.OutputPrefix()),
+				"Condition": condition,
+			},
+		},
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return "", fmt.Errorf("failed to build bucket policy: %w", err)
+	}
+	return string(data), nil
+}
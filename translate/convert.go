@@ -6,15 +6,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strings"
+
+	"github.com/megaproaktiv/audionote-config/workspace"
 )
 
 // Call ffmpeg
 // Transscript can not read m4a
-func ConvertM4AToMP3(inputFile string) (string, error) {
+func ConvertM4AToMP3(inputFile, jobID string) (string, error) {
 	// Member must satisfy regular expression pattern: ^[0-9a-zA-Z._-]+
-	validInputFile, err := CopyFileToValidName(inputFile)
+	validInputFile, err := CopyFileToValidName(inputFile, jobID)
 	if err != nil {
 		return "", err
 	}
@@ -30,30 +31,22 @@ func ConvertM4AToMP3(inputFile string) (string, error) {
 	return outputFile, nil
 }
 
-// CopyFileToValidName copies the file at src to a new valid name in the same directory.
-// It returns the new file name, or an error.
-func CopyFileToValidName(src string) (string, error) {
-	re := regexp.MustCompile(`[0-9a-zA-Z._-]+`)
-	dir := filepath.Dir(src)
-	base := filepath.Base(src)
-	ext := filepath.Ext(base)
-
-	// Get base name without extension, sanitize
-	namePart := base[:len(base)-len(ext)]
-	sanitizedBase := ""
-	for _, m := range re.FindAllString(namePart, -1) {
-		sanitizedBase += m
+// CopyFileToValidName copies the file at src to a valid name in the workspace staging directory.
+// It returns the new file name, or an error. The returned file is always a new copy,
+// so the caller may remove it
+func CopyFileToValidName(src, jobID string) (string, error) {
+	if err := workspace.Ensure(); err != nil {
+		return "", err
 	}
-	if sanitizedBase == "" {
-		sanitizedBase = "copy"
+	dst := workspace.StagingPath(src, jobID)
+
+	// Never copy a file onto itself, removing the copy would remove the input file
+	if filepath.Clean(dst) == filepath.Clean(src) {
+		return "", fmt.Errorf("%s is already the staged copy of job %s", src, jobID)
 	}
-	newName := sanitizedBase + ext
-	dst := filepath.Join(dir, newName)
 
-	// If src == dst, append _copy before ext
-	if dst == src {
-		newName = sanitizedBase + "_copy" + ext
-		dst = filepath.Join(dir, newName)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %v", err)
 	}
 
 	// Copy file
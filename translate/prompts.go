@@ -0,0 +1,88 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// isPromptFile reports whether name is a prompt-<action>.txt file
+func isPromptFile(name string) bool {
+	return strings.HasPrefix(name, "prompt-") && strings.HasSuffix(name, ".txt")
+}
+
+// SyncPrompts mirrors the prompt files directly below prefix into dir
+// Local prompt files missing in S3 are removed, nothing is uploaded
+func SyncPrompts(ctx context.Context, s3Client *s3.Client, bucket, prefix, dir string) (int, error) {
+	prefix = NormalizePrefix(prefix)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create team prompt directory: %v", err)
+	}
+
+	remote := map[string]bool{}
+	paginator := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list s3://%s/%s: %w", bucket, prefix, err)
+		}
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			name := strings.TrimPrefix(key, prefix)
+			if strings.Contains(name, "/") || !isPromptFile(name) {
+				continue
+			}
+			if err := downloadPrompt(ctx, s3Client, bucket, key, filepath.Join(dir, name)); err != nil {
+				return 0, err
+			}
+			remote[name] = true
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read team prompt directory: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isPromptFile(entry.Name()) || remote[entry.Name()] {
+			continue
+		}
+		fmt.Printf("Removing team prompt %s, it is no longer in S3\n", entry.Name())
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return 0, fmt.Errorf("failed to remove team prompt %s: %v", entry.Name(), err)
+		}
+	}
+	fmt.Printf("Synced %d team prompts from s3://%s/%s\n", len(remote), bucket, prefix)
+	return len(remote), nil
+}
+
+// downloadPrompt writes an object to file, replacing the file only after a complete download
+func downloadPrompt(ctx context.Context, s3Client *s3.Client, bucket, key, file string) error {
+	output, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to download s3://%s/%s: %w", bucket, key, err)
+	}
+	defer output.Body.Close()
+
+	content, err := io.ReadAll(output.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read s3://%s/%s: %w", bucket, key, err)
+	}
+	partial := file + ".part"
+	if err := os.WriteFile(partial, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", filepath.Base(file), err)
+	}
+	return os.Rename(partial, file)
+}
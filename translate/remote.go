@@ -0,0 +1,149 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/transcribe"
+)
+
+// JobNameMarker is part of every Transcribe job name created by this app
+const JobNameMarker = "-DMIN-"
+
+// RemoteJob summarizes a Transcribe job created by this app
+type RemoteJob struct {
+	Name          string
+	Status        string
+	Language      string
+	FailureReason string
+	CreatedAt     time.Time
+	StartedAt     time.Time
+	CompletedAt   time.Time
+}
+
+// Duration returns how long the job took to process, zero while it is running
+func (r RemoteJob) Duration() time.Duration {
+	if r.StartedAt.IsZero() || r.CompletedAt.IsZero() {
+		return 0
+	}
+	return r.CompletedAt.Sub(r.StartedAt)
+}
+
+// ListAppJobs lists all Transcribe jobs following this app's naming convention
+func ListAppJobs(ctx context.Context, client *transcribe.Client) ([]RemoteJob, error) {
+	var remoteJobs []RemoteJob
+	paginator := transcribe.NewListTranscriptionJobsPaginator(client, &transcribe.ListTranscriptionJobsInput{
+		JobNameContains: aws.String(JobNameMarker),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list transcription jobs: %w", err)
+		}
+		for _, summary := range page.TranscriptionJobSummaries {
+			remoteJobs = append(remoteJobs, RemoteJob{
+				Name:          aws.ToString(summary.TranscriptionJobName),
+				Status:        string(summary.TranscriptionJobStatus),
+				Language:      string(summary.LanguageCode),
+				FailureReason: aws.ToString(summary.FailureReason),
+				CreatedAt:     aws.ToTime(summary.CreationTime),
+				StartedAt:     aws.ToTime(summary.StartTime),
+				CompletedAt:   aws.ToTime(summary.CompletionTime),
+			})
+		}
+	}
+	fmt.Printf("Found %d transcription jobs of this app\n", len(remoteJobs))
+	return remoteJobs, nil
+}
+
+// ImportTranscript downloads the result of a completed job into the workspace cache
+// It returns the local transcript file
+func ImportTranscript(ctx context.Context, s3Client *s3.Client, jobName string, storage Storage) (string, error) {
+	if _, err := GetTranscriptText(ctx, s3Client, jobName, storage); err != nil {
+		return "", err
+	}
+	return TranscriptFile(jobName), nil
+}
+
+// DeleteJob deletes a Transcribe job together with its uploaded media and its output in S3
+// The objects are found from the URIs of the job, they may be in another bucket than the configured one
+func DeleteJob(ctx context.Context, client *transcribe.Client, s3Client *s3.Client, jobName string) error {
+	job, err := client.GetTranscriptionJob(ctx, &transcribe.GetTranscriptionJobInput{
+		TranscriptionJobName: aws.String(jobName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get transcription job %s: %w", jobName, err)
+	}
+
+	var uris []string
+	if transcript := job.TranscriptionJob.Transcript; transcript != nil {
+		uris = append(uris, aws.ToString(transcript.TranscriptFileUri))
+	}
+	if media := job.TranscriptionJob.Media; media != nil {
+		uris = append(uris, aws.ToString(media.MediaFileUri))
+	}
+	for _, uri := range uris {
+		if uri == "" {
+			continue
+		}
+		bucket, key, ok := s3ObjectFromURI(uri)
+		if !ok {
+			fmt.Printf("Not deleting %s, it is not an S3 object\n", uri)
+			continue
+		}
+		if err := DeleteObjects(ctx, s3Client, bucket, []string{key}); err != nil {
+			return err
+		}
+	}
+
+	_, err = client.DeleteTranscriptionJob(ctx, &transcribe.DeleteTranscriptionJobInput{
+		TranscriptionJobName: aws.String(jobName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete transcription job %s: %w", jobName, err)
+	}
+	fmt.Printf("Deleted transcription job %s\n", jobName)
+	return nil
+}
+
+// DeleteObjects deletes the given keys from the bucket
+func DeleteObjects(ctx context.Context, s3Client *s3.Client, bucket string, keys []string) error {
+	for _, key := range keys {
+		fmt.Printf("Deleting s3://%s/%s\n", bucket, key)
+		_, err := s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete s3://%s/%s: %w", bucket, key, err)
+		}
+	}
+	return nil
+}
+
+// s3ObjectFromURI returns bucket and key of an S3 URI of a Transcribe job: s3://bucket/key for the media,
+// a path-style https://s3.region.amazonaws.com/bucket/key or virtual-hosted URL for the transcript
+func s3ObjectFromURI(uri string) (string, string, bool) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", "", false
+	}
+	path := strings.TrimPrefix(parsed.Path, "/")
+	switch {
+	case parsed.Scheme == "s3":
+		return parsed.Host, path, parsed.Host != "" && path != ""
+	case parsed.Scheme != "https" || !strings.HasSuffix(parsed.Host, ".amazonaws.com"):
+		return "", "", false
+	case strings.HasPrefix(parsed.Host, "s3.") || strings.HasPrefix(parsed.Host, "s3-"):
+		bucket, key, found := strings.Cut(path, "/")
+		return bucket, key, found && bucket != "" && key != ""
+	default:
+		bucket, _, found := strings.Cut(parsed.Host, ".s3")
+		return bucket, path, found && bucket != "" && path != ""
+	}
+}
@@ -0,0 +1,35 @@
+package translate
+
+import "testing"
+
+func TestS3ObjectFromURI(t *testing.T) {
+	tests := []struct {
+		name   string
+		uri    string
+		bucket string
+		key    string
+		ok     bool
+	}{
+		{name: "s3 URI", uri: "s3://bucket/summary/talk.mp3", bucket: "bucket", key: "summary/talk.mp3", ok: true},
+		{name: "path-style", uri: "https://s3.eu-central-1.amazonaws.com/bucket/summary/output/job.json", bucket: "bucket", key: "summary/output/job.json", ok: true},
+		{name: "legacy path-style", uri: "https://s3-eu-west-1.amazonaws.com/bucket/job.json", bucket: "bucket", key: "job.json", ok: true},
+		{name: "virtual-hosted", uri: "https://bucket.s3.eu-central-1.amazonaws.com/summary/output/job.json", bucket: "bucket", key: "summary/output/job.json", ok: true},
+		{name: "s3 URI without key", uri: "s3://bucket/", ok: false},
+		{name: "path-style without key", uri: "https://s3.eu-central-1.amazonaws.com/bucket", ok: false},
+		{name: "other host", uri: "https://example.com/bucket/key", ok: false},
+		{name: "http", uri: "http://s3.eu-central-1.amazonaws.com/bucket/key", ok: false},
+		{name: "empty", uri: "", ok: false},
+		{name: "invalid", uri: "s3://%zz", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, key, ok := s3ObjectFromURI(tt.uri)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && (bucket != tt.bucket || key != tt.key) {
+				t.Errorf("s3ObjectFromURI = %q %q, want %q %q", bucket, key, tt.bucket, tt.key)
+			}
+		})
+	}
+}
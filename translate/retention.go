@@ -0,0 +1,140 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/megaproaktiv/audionote-config/jobs"
+)
+
+// Retention settings for uploaded audio and transcripts
+const (
+	RetentionDelete  = "delete"
+	RetentionDays    = "days"
+	RetentionForever = "forever"
+)
+
+// LifecycleRuleID identifies the lifecycle rules managed by this app, it is the prefix of their IDs
+const LifecycleRuleID = "audionote-retention"
+
+// DeleteJobObjects removes the uploaded audio and the transcription result of a job from S3
+func DeleteJobObjects(ctx context.Context, s3Client *s3.Client, job *jobs.Job, storage Storage) error {
+	var keys []string
+	if job.S3Key != "" {
+		keys = append(keys, job.S3Key)
+	}
+	if job.TranscribeJob != "" {
+		keys = append(keys, storage.OutputKey(job.TranscribeJob))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return DeleteObjects(ctx, s3Client, storage.Bucket, keys)
+}
+
+// LifecycleDays returns the expiration in days matching a retention setting
+// Zero means no lifecycle rule is needed
+func LifecycleDays(retention string, days int) int {
+	switch retention {
+	case RetentionDelete:
+		// One day is the shortest expiration S3 lifecycle rules support
+		return 1
+	case RetentionDays:
+		return max(days, 1)
+	default:
+		return 0
+	}
+}
+
+// isAppLifecycleRule reports whether a lifecycle rule is managed by this app
+func isAppLifecycleRule(id string) bool {
+	return id == LifecycleRuleID || strings.HasPrefix(id, LifecycleRuleID+"-")
+}
+
+// lifecycleRules returns the app's lifecycle rules: the uploaded audio and the AWS Transcribe
+// results expire, other objects below the prefix, e.g. of other users or teams, are kept
+func lifecycleRules(storage Storage, days int) []types.LifecycleRule {
+	var rules []types.LifecycleRule
+	for _, prefix := range storage.LifecyclePrefixes() {
+		name := strings.TrimSuffix(strings.TrimPrefix(prefix, storage.Prefix), "/")
+		rules = append(rules, types.LifecycleRule{
+			ID:     aws.String(LifecycleRuleID + "-" + name),
+			Status: types.ExpirationStatusEnabled,
+			Filter: &types.LifecycleRuleFilter{
+				Prefix: aws.String(prefix),
+			},
+			Expiration: &types.LifecycleExpiration{
+				Days: aws.Int32(int32(days)),
+			},
+			AbortIncompleteMultipartUpload: &types.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: aws.Int32(1),
+			},
+		})
+	}
+	return rules
+}
+
+// DescribeLifecycleRule tells what the app's lifecycle rule for the storage does
+func DescribeLifecycleRule(storage Storage, days int) string {
+	if days <= 0 {
+		return fmt.Sprintf("The lifecycle rules %s-* are removed from bucket '%s', objects are kept.", LifecycleRuleID, storage.Bucket)
+	}
+	return fmt.Sprintf("Lifecycle rules %s-audio and %s-output on bucket '%s':\nexpire the objects under %s and %s after %d days.\nOther objects under %s are not affected.",
+		LifecycleRuleID, LifecycleRuleID, storage.Bucket, storage.AudioPrefix(), storage.OutputPrefix(), days, storage.Prefix)
+}
+
+// ApplyLifecycleRule installs or updates the app's lifecycle rules for the audio and output
+// prefixes of the storage. Rules not managed by this app are kept. With days <= 0 the app's rules are removed
+func ApplyLifecycleRule(ctx context.Context, s3Client *s3.Client, storage Storage, days int) error {
+	bucket := storage.Bucket
+	var rules []types.LifecycleRule
+	existing, err := s3Client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		if !strings.Contains(err.Error(), "NoSuchLifecycleConfiguration") {
+			return fmt.Errorf("failed to read lifecycle configuration of %s: %w", bucket, err)
+		}
+	} else {
+		for _, rule := range existing.Rules {
+			if !isAppLifecycleRule(aws.ToString(rule.ID)) {
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	if days > 0 {
+		rules = append(rules, lifecycleRules(storage, days)...)
+	}
+
+	if len(rules) == 0 {
+		_, err = s3Client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
+			Bucket: aws.String(bucket),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete lifecycle configuration of %s: %w", bucket, err)
+		}
+		fmt.Printf("Removed lifecycle rules %s-* from bucket %s\n", LifecycleRuleID, bucket)
+		return nil
+	}
+
+	_, err = s3Client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{
+			Rules: rules,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write lifecycle configuration of %s: %w", bucket, err)
+	}
+	if days > 0 {
+		fmt.Printf("Installed lifecycle rules %s-* on bucket %s: expire %s and %s after %d days\n", LifecycleRuleID, bucket, storage.AudioPrefix(), storage.OutputPrefix(), days)
+	} else {
+		fmt.Printf("Removed lifecycle rules %s-* from bucket %s\n", LifecycleRuleID, bucket)
+	}
+	return nil
+}
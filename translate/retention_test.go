@@ -0,0 +1,43 @@
+package translate
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestLifecycleRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{name: "default prefix", prefix: "", want: []string{"summary/audio/", "summary/output/"}},
+		{name: "nested prefix", prefix: "users/jane", want: []string{"users/jane/audio/", "users/jane/output/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := NewStorage("bucket", tt.prefix, SSENone, "")
+			var prefixes []string
+			for _, rule := range lifecycleRules(storage, 7) {
+				if !isAppLifecycleRule(aws.ToString(rule.ID)) {
+					t.Errorf("rule %s is not recognized as rule of the app", aws.ToString(rule.ID))
+				}
+				if days := aws.ToInt32(rule.Expiration.Days); days != 7 {
+					t.Errorf("rule %s expires after %d days, want 7", aws.ToString(rule.ID), days)
+				}
+				prefixes = append(prefixes, aws.ToString(rule.Filter.Prefix))
+			}
+			if !slices.Equal(prefixes, tt.want) {
+				t.Errorf("rule prefixes = %v, want %v", prefixes, tt.want)
+			}
+			if key := storage.MediaKey("/tmp/talk.mp3"); key != tt.want[0]+"talk.mp3" {
+				t.Errorf("MediaKey = %q, not under %s", key, tt.want[0])
+			}
+			if key := storage.OutputKey("job"); key != tt.want[1]+"job.json" {
+				t.Errorf("OutputKey = %q, not under %s", key, tt.want[1])
+			}
+		})
+	}
+}
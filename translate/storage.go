@@ -0,0 +1,129 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// DefaultPrefix is the S3 key prefix used when none is configured
+const DefaultPrefix = "summary/"
+
+// Server-side encryption modes for uploads and Transcribe output
+const (
+	SSENone = ""
+	SSES3   = "AES256"
+	SSEKMS  = "aws:kms"
+)
+
+// Storage describes where and how this app writes objects to S3
+type Storage struct {
+	Bucket   string
+	Prefix   string
+	SSE      string
+	KMSKeyID string
+}
+
+// NewStorage creates storage settings with a normalized key prefix
+func NewStorage(bucket, prefix, sse, kmsKeyID string) Storage {
+	return Storage{
+		Bucket:   strings.TrimSpace(bucket),
+		Prefix:   NormalizePrefix(prefix),
+		SSE:      strings.TrimSpace(sse),
+		KMSKeyID: strings.TrimSpace(kmsKeyID),
+	}
+}
+
+// NormalizePrefix strips leading slashes and ensures a trailing slash
+// An empty prefix falls back to DefaultPrefix
+func NormalizePrefix(prefix string) string {
+	prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+	if prefix == "" {
+		return DefaultPrefix
+	}
+	return prefix + "/"
+}
+
+// AudioPrefix returns the prefix the audio files are uploaded below
+func (s Storage) AudioPrefix() string {
+	return s.Prefix + "audio/"
+}
+
+// OutputPrefix returns the prefix AWS Transcribe writes the results below
+func (s Storage) OutputPrefix() string {
+	return s.Prefix + "output/"
+}
+
+// LifecyclePrefixes returns the prefixes of the objects the app writes, which the retention rules expire
+func (s Storage) LifecyclePrefixes() []string {
+	return []string{s.AudioPrefix(), s.OutputPrefix()}
+}
+
+// MediaKey returns the S3 key an audio file is uploaded to
+func (s Storage) MediaKey(file string) string {
+	return s.AudioPrefix() + filepath.Base(file)
+}
+
+// OutputKey returns the S3 key AWS Transcribe writes the result of a job to
+func (s Storage) OutputKey(jobName string) string {
+	return fmt.Sprintf("%s%s.json", s.OutputPrefix(), jobName)
+}
+
+// Validate checks the prefix and encryption settings for consistency
+func (s Storage) Validate() error {
+	if strings.Contains(s.Prefix, "//") {
+		return fmt.Errorf("key prefix %q contains an empty path segment", s.Prefix)
+	}
+	switch s.SSE {
+	case SSENone, SSES3:
+		if s.KMSKeyID != "" {
+			return fmt.Errorf("a KMS key ID is only used with SSE mode %s", SSEKMS)
+		}
+	case SSEKMS:
+		if s.KMSKeyID == "" {
+			return fmt.Errorf("SSE mode %s requires a KMS key ID", SSEKMS)
+		}
+	default:
+		return fmt.Errorf("unknown SSE mode %q, use %q, %q or %q", s.SSE, SSENone, SSES3, SSEKMS)
+	}
+	return nil
+}
+
+// ApplyEncryption sets the configured server-side encryption on an upload
+func (s Storage) ApplyEncryption(input *s3.PutObjectInput) {
+	switch s.SSE {
+	case SSES3:
+		input.ServerSideEncryption = types.ServerSideEncryptionAes256
+	case SSEKMS:
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		input.SSEKMSKeyId = aws.String(s.KMSKeyID)
+	}
+}
+
+// CheckWriteAccess uploads and deletes a small object below the prefix
+// using the configured encryption, to verify prefix permissions and KMS key access
+func CheckWriteAccess(ctx context.Context, s3Client *s3.Client, s Storage) error {
+	key := s.Prefix + ".audionote-write-check"
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+		Body:   strings.NewReader("audionote write check"),
+	}
+	s.ApplyEncryption(input)
+	if _, err := s3Client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to write s3://%s/%s: %w", s.Bucket, key, err)
+	}
+	_, err := s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete s3://%s/%s: %w", s.Bucket, key, err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package translate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizePrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"", DefaultPrefix},
+		{"  ", DefaultPrefix},
+		{"/", DefaultPrefix},
+		{"team", "team/"},
+		{"/team/audio/", "team/audio/"},
+		{" team/audio ", "team/audio/"},
+	}
+	for _, tt := range tests {
+		if got := NormalizePrefix(tt.prefix); got != tt.want {
+			t.Errorf("NormalizePrefix(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestStorageValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage Storage
+		err     string
+	}{
+		{name: "no encryption", storage: NewStorage("bucket", "", SSENone, "")},
+		{name: "SSE-S3", storage: NewStorage("bucket", "audio", SSES3, "")},
+		{name: "SSE-KMS", storage: NewStorage("bucket", "audio", SSEKMS, "alias/audio")},
+		{name: "SSE-KMS without key", storage: NewStorage("bucket", "audio", SSEKMS, " "), err: "requires a KMS key ID"},
+		{name: "key without SSE-KMS", storage: NewStorage("bucket", "audio", SSES3, "alias/audio"), err: "only used with SSE mode"},
+		{name: "key without encryption", storage: NewStorage("bucket", "audio", SSENone, "alias/audio"), err: "only used with SSE mode"},
+		{name: "unknown mode", storage: NewStorage("bucket", "audio", "aws:kms:dsse", ""), err: "unknown SSE mode"},
+		{name: "empty path segment", storage: NewStorage("bucket", "team//audio", SSENone, ""), err: "empty path segment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.storage.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -3,34 +3,62 @@ package translate
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/transcribe"
 	"github.com/aws/aws-sdk-go-v2/service/transcribe/types"
+	"github.com/megaproaktiv/audionote-config/workspace"
 )
 
+// MaxSpeakers is the number of speakers AWS Transcribe tells apart
+const MaxSpeakers = 10
+
 type TranscriptResponse struct {
 	Results struct {
 		Transcripts []struct {
 			Transcript string `json:"transcript"`
 		} `json:"transcripts"`
+		// SpeakerLabels is only present for jobs started with speaker labels
+		SpeakerLabels *struct {
+			Speakers int `json:"speakers"`
+		} `json:"speaker_labels,omitempty"`
+		Items []struct {
+			StartTime    string `json:"start_time"`
+			EndTime      string `json:"end_time"`
+			Type         string `json:"type"`
+			SpeakerLabel string `json:"speaker_label"`
+		} `json:"items"`
 	} `json:"results"`
 }
 
+// TranscriptDetails is the transcript text with the metadata of the recording
+type TranscriptDetails struct {
+	Text string
+	// Duration is the end of the last spoken word
+	Duration time.Duration
+	// Speakers is the number of speakers told apart, 0 if unknown
+	Speakers int
+}
+
 // StartTranscribeJob starts an AWS Transcribe job with the specified language code
 // Supported language codes include: en-US, de-DE, fr-FR, es-ES, etc.
 // See AWS Transcribe documentation for full list of supported languages
-func StartTranscribeJob(ctx context.Context, client *transcribe.Client, bucket, mp3Key, languageCode string) (string, error) {
-	jobName := strings.TrimSuffix(filepath.Base(mp3Key), ".mp3") + "-DMIN-" + fmt.Sprintf("%d", time.Now().Unix())
-	mediaURI := fmt.Sprintf("s3://%s/%s", bucket, mp3Key)
+// The result is written below the storage prefix, encrypted with the storage KMS key if configured
+// speakerLabels tells up to MaxSpeakers speakers apart
+func StartTranscribeJob(ctx context.Context, client *transcribe.Client, storage Storage, mp3Key, languageCode string, speakerLabels bool) (string, error) {
+	jobName := strings.TrimSuffix(filepath.Base(mp3Key), ".mp3") + JobNameMarker + fmt.Sprintf("%d", time.Now().Unix())
+	mediaURI := fmt.Sprintf("s3://%s/%s", storage.Bucket, mp3Key)
 	fmt.Printf("Starting transcription job '%s' for %s with language %s...\n", jobName, mediaURI, languageCode)
-	outputKey := fmt.Sprintf("summary/output/%s.json", jobName)
+	outputKey := storage.OutputKey(jobName)
 	mediaFormat := types.MediaFormatM4a
 
 	// Convert language code to AWS Transcribe format
@@ -50,9 +78,18 @@ func StartTranscribeJob(ctx context.Context, client *transcribe.Client, bucket,
 		LanguageCode:         languageCodeType,
 		MediaFormat:          mediaFormat,
 		MediaSampleRateHertz: aws.Int32(48000),
-		OutputBucketName:     &bucket,
+		OutputBucketName:     &storage.Bucket,
 		OutputKey:            &outputKey,
 	}
+	if speakerLabels {
+		params.Settings = &types.Settings{
+			ShowSpeakerLabels: aws.Bool(true),
+			MaxSpeakerLabels:  aws.Int32(MaxSpeakers),
+		}
+	}
+	if storage.SSE == SSEKMS {
+		params.OutputEncryptionKMSKeyId = &storage.KMSKeyID
+	}
 	resp, err := client.StartTranscriptionJob(ctx, &params)
 	if err != nil {
 		return "", err
@@ -60,63 +97,109 @@ func StartTranscribeJob(ctx context.Context, client *transcribe.Client, bucket,
 	return *resp.TranscriptionJob.TranscriptionJobName, nil
 }
 
-func WaitForTranscribeJob(jobName string) error {
+// ErrTranscribeJobFailed is returned when AWS Transcribe reports a failed job
+var ErrTranscribeJobFailed = errors.New("transcription job failed")
+
+// WaitForTranscribeJob polls the transcription job until it is completed or failed
+func WaitForTranscribeJob(ctx context.Context, client *transcribe.Client, jobName string) error {
 	fmt.Printf("Waiting for transcription job '%s' to complete...\n", jobName)
 	for {
-		cmd := exec.Command("aws", "transcribe", "get-transcription-job",
-			"--transcription-job-name", jobName)
-		output, err := cmd.Output()
+		resp, err := client.GetTranscriptionJob(ctx, &transcribe.GetTranscriptionJobInput{
+			TranscriptionJobName: &jobName,
+		})
 		if err != nil {
 			return err
 		}
-		var resp map[string]any
-		err = json.Unmarshal(output, &resp)
-		if err != nil {
-			return err
-		}
-		job, ok := resp["TranscriptionJob"].(map[string]any)
-		if !ok {
+		if resp.TranscriptionJob == nil {
 			return fmt.Errorf("unexpected response format")
 		}
-		status, ok := job["TranscriptionJobStatus"].(string)
-		if !ok {
-			return fmt.Errorf("unexpected status format")
-		}
+		status := resp.TranscriptionJob.TranscriptionJobStatus
 		fmt.Printf("Current status: %s\n", status)
-		if status == "COMPLETED" {
+		if status == types.TranscriptionJobStatusCompleted {
 			break
-		} else if status == "FAILED" {
-			return fmt.Errorf("transcription job failed")
+		} else if status == types.TranscriptionJobStatusFailed {
+			return fmt.Errorf("%w: %s", ErrTranscribeJobFailed, aws.ToString(resp.TranscriptionJob.FailureReason))
 		}
 		time.Sleep(10 * time.Second)
 	}
 	return nil
 }
 
-func GetTranscriptText(jobName, bucket string) (string, error) {
-	s3Key := fmt.Sprintf("summary/output/%s.json", jobName)
-	localFile := s3Key
-	if err := os.MkdirAll(filepath.Dir(localFile), os.ModePerm); err != nil {
+// TranscriptFile returns the local workspace location of a transcription job result
+func TranscriptFile(jobName string) string {
+	return filepath.Join(workspace.OutputDir(), jobName+".json")
+}
+
+// GetTranscriptText downloads the result of a job into the workspace and returns the transcript text
+func GetTranscriptText(ctx context.Context, s3Client *s3.Client, jobName string, storage Storage) (string, error) {
+	s3Key := storage.OutputKey(jobName)
+	localFile := TranscriptFile(jobName)
+	if err := workspace.Ensure(); err != nil {
+		return "", err
+	}
+	fmt.Printf("Fetching transcription result from s3://%s/%s...\n", storage.Bucket, s3Key)
+	output, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(storage.Bucket),
+		Key:    aws.String(s3Key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to download transcription result of job %s: %w", jobName, err)
+	}
+	defer output.Body.Close()
+
+	file, err := os.Create(localFile)
+	if err != nil {
 		return "", err
 	}
-	s3Path := fmt.Sprintf("s3://%s/%s", bucket, s3Key)
-	fmt.Printf("Fetching transcription result from %s...\n", s3Path)
-	cmd := exec.Command("aws", "s3", "cp", s3Path, localFile)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
+	if _, err := io.Copy(file, output.Body); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write %s: %w", localFile, err)
+	}
+	if err := file.Close(); err != nil {
 		return "", err
 	}
-	data, err := os.ReadFile(localFile)
+	return ReadTranscriptFile(localFile)
+}
+
+// ReadTranscriptFile extracts the transcript text from a downloaded transcription result
+func ReadTranscriptFile(localFile string) (string, error) {
+	details, err := ReadTranscriptDetails(localFile)
 	if err != nil {
 		return "", err
 	}
+	return details.Text, nil
+}
+
+// ReadTranscriptDetails extracts the transcript text, duration and speakers from a downloaded
+// transcription result
+func ReadTranscriptDetails(localFile string) (TranscriptDetails, error) {
+	var details TranscriptDetails
+	data, err := os.ReadFile(localFile)
+	if err != nil {
+		return details, err
+	}
 	var transcriptResp TranscriptResponse
 	if err := json.Unmarshal(data, &transcriptResp); err != nil {
-		return "", err
+		return details, err
 	}
 	if len(transcriptResp.Results.Transcripts) == 0 {
-		return "", fmt.Errorf("no transcript found")
+		return details, fmt.Errorf("no transcript found")
+	}
+	details.Text = transcriptResp.Results.Transcripts[0].Transcript
+
+	speakers := map[string]bool{}
+	for _, item := range transcriptResp.Results.Items {
+		if item.SpeakerLabel != "" {
+			speakers[item.SpeakerLabel] = true
+		}
+		// Punctuation items have no times
+		if end, err := strconv.ParseFloat(item.EndTime, 64); err == nil {
+			details.Duration = max(details.Duration, time.Duration(end*float64(time.Second)))
+		}
+	}
+	details.Speakers = len(speakers)
+	if transcriptResp.Results.SpeakerLabels != nil && transcriptResp.Results.SpeakerLabels.Speakers > 0 {
+		details.Speakers = transcriptResp.Results.SpeakerLabels.Speakers
 	}
-	return transcriptResp.Results.Transcripts[0].Transcript, nil
+	return details, nil
 }
@@ -1,22 +1,156 @@
 package translate
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
-func CopyToS3(file, bucket string) (string, error) {
-	s3Key := "summary/" + filepath.Base(file)
-	dest := fmt.Sprintf("s3://%s/%s", bucket, s3Key)
-	fmt.Printf("Copying %s to %s...\n", file, dest)
-	cmd := exec.Command("aws", "s3", "cp", file, dest)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err := cmd.Run()
+// UploadPartSize is the part size of multipart uploads
+const UploadPartSize = 8 * 1024 * 1024
+
+// UploadConcurrency is the number of parts uploaded in parallel
+const UploadConcurrency = 4
+
+// hashMetadataKey is the object metadata entry holding the SHA-256 of the uploaded file
+const hashMetadataKey = "sha256"
+
+// ProgressFunc receives the number of uploaded bytes and the total size
+type ProgressFunc func(uploaded, total int64)
+
+// progressReader reports the bytes read from the file to a ProgressFunc
+type progressReader struct {
+	reader   io.Reader
+	read     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.progress != nil && n > 0 {
+		r.progress(r.read, r.total)
+	}
+	return n, err
+}
+
+// CopyToS3 uploads a file below the storage prefix with a multipart, concurrent upload.
+// fileHash is the hex encoded SHA-256 of the file. The upload is skipped when an object
+// with the same hash already exists under the key, otherwise the object checksum is
+// verified against the file after the upload. progress may be nil
+func CopyToS3(ctx context.Context, s3Client *s3.Client, file string, storage Storage, fileHash string, progress ProgressFunc) (string, error) {
+	s3Key := storage.MediaKey(file)
+	dest := fmt.Sprintf("s3://%s/%s", storage.Bucket, s3Key)
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", err
+	}
+
+	// Skip the upload if the same content is already stored under the key
+	head, err := s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(storage.Bucket),
+		Key:    aws.String(s3Key),
+	})
+	if err == nil && head.Metadata[hashMetadataKey] == fileHash {
+		fmt.Printf("%s already contains %s, skipping upload\n", dest, file)
+		if progress != nil {
+			progress(info.Size(), info.Size())
+		}
+		return s3Key, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	fmt.Printf("Uploading %s (%d bytes) to %s...\n", file, info.Size(), dest)
+	input := &s3.PutObjectInput{
+		Bucket:            aws.String(storage.Bucket),
+		Key:               aws.String(s3Key),
+		Body:              &progressReader{reader: f, total: info.Size(), progress: progress},
+		ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+		Metadata:          map[string]string{hashMetadataKey: fileHash},
+	}
+	storage.ApplyEncryption(input)
+
+	uploader := manager.NewUploader(s3Client, func(u *manager.Uploader) {
+		u.PartSize = UploadPartSize
+		u.Concurrency = UploadConcurrency
+	})
+	output, err := uploader.Upload(ctx, input)
 	if err != nil {
 		return "", err
 	}
+
+	if err := verifyChecksum(file, aws.ToString(output.ChecksumSHA256)); err != nil {
+		return "", fmt.Errorf("upload of %s to %s: %w", file, dest, err)
+	}
+	fmt.Printf("Uploaded %s, checksum verified\n", dest)
 	return s3Key, nil
 }
+
+// verifyChecksum compares the SHA-256 checksum S3 computed with the local file
+// Multipart uploads have a composite checksum: the hash of the part hashes with a "-<parts>" suffix
+func verifyChecksum(file, remote string) error {
+	if remote == "" {
+		return fmt.Errorf("no checksum returned by S3")
+	}
+	composite := strings.Contains(remote, "-")
+	local, err := fileChecksum(file, composite)
+	if err != nil {
+		return err
+	}
+	if local != remote {
+		return fmt.Errorf("checksum mismatch: local %s, S3 %s", local, remote)
+	}
+	return nil
+}
+
+// fileChecksum computes the base64 SHA-256 checksum of a file the way S3 reports it,
+// either over the whole file or composite over parts of UploadPartSize
+func fileChecksum(file string, composite bool) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if !composite {
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+	}
+
+	partHashes := sha256.New()
+	parts := 0
+	for {
+		h := sha256.New()
+		n, err := io.CopyN(h, f, UploadPartSize)
+		if n > 0 {
+			partHashes.Write(h.Sum(nil))
+			parts++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(partHashes.Sum(nil)), parts), nil
+}
@@ -0,0 +1,58 @@
+package translate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	small := []byte("audio")
+	large := bytes.Repeat([]byte{1}, UploadPartSize+10)
+
+	whole := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		return base64.StdEncoding.EncodeToString(sum[:])
+	}
+	composite := func(data []byte) string {
+		first, second := sha256.Sum256(data[:UploadPartSize]), sha256.Sum256(data[UploadPartSize:])
+		sum := sha256.Sum256(append(first[:], second[:]...))
+		return fmt.Sprintf("%s-2", base64.StdEncoding.EncodeToString(sum[:]))
+	}
+
+	tests := []struct {
+		name   string
+		data   []byte
+		remote string
+		err    string
+	}{
+		{name: "whole file", data: small, remote: whole(small)},
+		{name: "multipart", data: large, remote: composite(large)},
+		{name: "mismatch", data: small, remote: whole([]byte("other")), err: "checksum mismatch"},
+		{name: "whole checksum of a multipart upload", data: large, remote: whole(large) + "-2", err: "checksum mismatch"},
+		{name: "no checksum", data: small, remote: "", err: "no checksum"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "audio.mp3")
+			if err := os.WriteFile(file, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			err := verifyChecksum(file, tt.remote)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -5,59 +5,112 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/transcribe"
 	awsutil "github.com/megaproaktiv/audionote-config/aws"
+	"github.com/megaproaktiv/audionote-config/jobs"
 )
 
 var Client *transcribe.Client
+var S3Client *s3.Client
 
-func InitClient(ctx context.Context, profile string) error {
-	cfg, err := awsutil.LoadAndValidateAWSConfig(ctx, profile)
+// InitClient sets the Transcribe and S3 clients of the AWS session to the region of the bucket
+// AWS Transcribe only accepts media and output buckets in its own region,
+// so the profile's default region is overridden. An empty region is looked up
+// from the bucket. The region in use is returned, so callers can cache it
+func InitClient(ctx context.Context, profile, bucket, region string) (string, error) {
+	session, err := awsutil.GetSession(ctx, profile)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	Client = transcribe.NewFromConfig(cfg)
-	return nil
+	if region == "" && bucket != "" {
+		region, err = awsutil.BucketRegion(ctx, session.Config, bucket)
+		if err != nil {
+			return "", fmt.Errorf("failed to determine region of bucket %s: %w", bucket, err)
+		}
+	}
+	if region == "" {
+		region = session.Config.Region
+	}
+	fmt.Printf("Using region %s for AWS Transcribe and S3\n", region)
+
+	Client = session.Transcribe(region)
+	S3Client = session.S3(region)
+	return region, nil
 }
 
 // Translate converts an audio file and transcribes it using AWS Transcribe
-// inputFile: path to the input audio file (M4A or MP3)
-// bucket: S3 bucket name for storing temporary files
-// languageCode: language code for transcription (e.g., "en-US", "de-DE")
-func Translate(ctx context.Context, client *transcribe.Client, inputFile string, bucket string, languageCode string) string {
-
-	mp3File, err := CopyFileToValidName(inputFile)
+// job: the persisted job carrying the input file, S3 bucket and language code
+// storage: S3 prefix and encryption settings for the job's bucket
+// progress: called while the audio file is uploaded, may be nil
+// Every finished step is recorded in the job, so an interrupted job resumes
+// at the Transcribe job it already started or with the transcript it already fetched.
+// A failed step marks the job as failed with its error
+func Translate(ctx context.Context, client *transcribe.Client, s3Client *s3.Client, job *jobs.Job, storage Storage, progress ProgressFunc) (string, error) {
+	transcript, err := translateSteps(ctx, client, s3Client, job, storage, progress)
 	if err != nil {
-		log.Fatalf("Error copy file: %v", err)
+		job.Fail(err)
+		return "", err
 	}
+	return transcript, nil
+}
 
-	mp3Key, err := CopyToS3(mp3File, bucket)
-	if err != nil {
-		log.Fatalf("Error copying file to S3: %v", err)
-	}
+// translateSteps runs the steps of Translate from the stage the job has reached
+func translateSteps(ctx context.Context, client *transcribe.Client, s3Client *s3.Client, job *jobs.Job, storage Storage, progress ProgressFunc) (string, error) {
 
-	jobName, err := StartTranscribeJob(ctx, client, bucket, mp3Key, languageCode)
-	if err != nil {
-		log.Fatalf("Error starting transcription job: %v", err)
+	// Transcript already downloaded, only the LLM stage is missing
+	if job.Stage == jobs.StageTranscribed && job.TranscriptFile != "" {
+		transcript, err := ReadTranscriptFile(job.TranscriptFile)
+		if err == nil {
+			fmt.Printf("Using transcript of job %s from %s\n", job.ID, job.TranscriptFile)
+			return transcript, nil
+		}
+		fmt.Printf("Could not read transcript %s, fetching it again: %v\n", job.TranscriptFile, err)
 	}
 
-	if err := WaitForTranscribeJob(jobName); err != nil {
-		log.Fatalf("Error waiting for transcription job: %v", err)
+	if job.TranscribeJob == "" {
+		mp3File, err := CopyFileToValidName(job.InputFile, job.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to copy %s: %w", job.InputFile, err)
+		}
+		job.SetStage(jobs.StageStaged)
+
+		mp3Key, err := CopyToS3(ctx, s3Client, mp3File, storage, job.FileHash, progress)
+		if err != nil {
+			return "", fmt.Errorf("failed to copy file to S3: %w", err)
+		}
+		job.S3Key = mp3Key
+		job.SetStage(jobs.StageUploaded)
+
+		// Clean up the copied file and the staging directory of the job
+		if err := os.Remove(mp3File); err != nil {
+			log.Printf("Warning: Could not remove temporary file %s: %v", mp3File, err)
+		} else {
+			fmt.Printf("Cleaned up temporary file: %s\n", mp3File)
+			os.Remove(filepath.Dir(mp3File))
+		}
+
+		jobName, err := StartTranscribeJob(ctx, client, storage, mp3Key, job.Language, job.SpeakerLabels)
+		if err != nil {
+			return "", fmt.Errorf("failed to start transcription job: %w", err)
+		}
+		job.TranscribeJob = jobName
+		job.SetStage(jobs.StageTranscribing)
 	}
 
-	transcript, err := GetTranscriptText(jobName, bucket)
-	if err != nil {
-		log.Fatalf("Error getting transcript text: %v", err)
+	if err := WaitForTranscribeJob(ctx, client, job.TranscribeJob); err != nil {
+		return "", fmt.Errorf("failed waiting for transcription job: %w", err)
 	}
 
-	// Clean up the copied file
-	if err := os.Remove(mp3File); err != nil {
-		log.Printf("Warning: Could not remove temporary file %s: %v", mp3File, err)
-	} else {
-		fmt.Printf("Cleaned up temporary file: %s", mp3File)
+	transcript, err := GetTranscriptText(ctx, s3Client, job.TranscribeJob, storage)
+	if err != nil {
+		return "", fmt.Errorf("failed to get transcript text: %w", err)
 	}
+	job.TranscriptFile = TranscriptFile(job.TranscribeJob)
+	job.SetStage(jobs.StageTranscribed)
 
-	return transcript
+	return transcript, nil
 }
@@ -0,0 +1,85 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// validName matches the characters AWS Transcribe accepts in media and job names
+var validName = regexp.MustCompile(`[0-9a-zA-Z._-]+`)
+
+// Dir returns the app-managed workspace directory in the user's cache dir
+// All temporary and derived files (staged audio, transcripts) live below it
+func Dir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return filepath.Join(cacheDir, "audionote")
+}
+
+// StagingDir holds sanitized copies of input audio files
+func StagingDir() string {
+	return filepath.Join(Dir(), "staging")
+}
+
+// OutputDir holds downloaded AWS Transcribe results
+func OutputDir() string {
+	return filepath.Join(Dir(), "output")
+}
+
+// SanitizeName strips all characters AWS Transcribe does not accept from a file name
+// The extension is kept, an empty base name becomes "copy"
+func SanitizeName(name string) string {
+	ext := filepath.Ext(name)
+	namePart := name[:len(name)-len(ext)]
+	sanitizedBase := ""
+	for _, m := range validName.FindAllString(namePart, -1) {
+		sanitizedBase += m
+	}
+	if sanitizedBase == "" {
+		sanitizedBase = "copy"
+	}
+	return sanitizedBase + ext
+}
+
+// StagingPath returns the staging location of an input file for a job
+// Each job stages into its own directory, so files of the same name from different
+// folders are kept apart. The file name stays the sanitized base name of the input
+func StagingPath(src, jobID string) string {
+	return filepath.Join(StagingDir(), SanitizeName(jobID), SanitizeName(filepath.Base(src)))
+}
+
+// Ensure creates the workspace directories if they do not exist
+func Ensure() error {
+	for _, dir := range []string{StagingDir(), OutputDir()} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create workspace directory %s: %v", dir, err)
+		}
+	}
+	return nil
+}
+
+// Clean removes all files from the workspace and returns the number of removed files
+func Clean() (int, error) {
+	removed := 0
+	for _, dir := range []string{StagingDir(), OutputDir()} {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return removed, fmt.Errorf("failed to read workspace directory %s: %v", dir, err)
+		}
+		for _, entry := range entries {
+			if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %v", entry.Name(), err)
+			}
+			removed++
+		}
+	}
+	fmt.Printf("Cleaned workspace %s: removed %d files\n", Dir(), removed)
+	return removed, nil
+}